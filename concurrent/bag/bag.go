@@ -0,0 +1,91 @@
+// Package bag provides a multiset which counts its elements and remembers the
+// order in which they were first added.
+package bag
+
+import (
+	"iter"
+	"sync"
+
+	"github.com/eyotang/container/concurrent/queue"
+)
+
+// Bag is a goroutine-safe multiset. Each distinct element keeps its place in
+// a ring-buffer queue from its first Add until its count drops to zero.
+type Bag[T comparable] struct {
+	lock   sync.RWMutex
+	counts map[T]int
+	order  *queue.Queue[T]
+}
+
+// NewBag constructs and returns a new, empty Bag.
+func NewBag[T comparable]() *Bag[T] {
+	return &Bag[T]{
+		counts: make(map[T]int),
+		order:  queue.NewQueue[T](),
+	}
+}
+
+// Add adds one occurrence of elem. A new element is placed after every
+// element already in the bag.
+func (b *Bag[T]) Add(elem T) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.counts[elem] == 0 {
+		b.order.Push(elem)
+	}
+	b.counts[elem]++
+}
+
+// Count returns the number of occurrences of elem.
+func (b *Bag[T]) Count(elem T) int {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return b.counts[elem]
+}
+
+// Remove removes one occurrence of elem and returns true, or returns false if
+// elem is not in the bag. Removing the last occurrence also drops elem from
+// the order, which is O(n) in the number of distinct elements.
+func (b *Bag[T]) Remove(elem T) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	n, ok := b.counts[elem]
+	if !ok {
+		return false
+	}
+	if n > 1 {
+		b.counts[elem] = n - 1
+		return true
+	}
+	delete(b.counts, elem)
+	b.order.MoveToFront(b.order.Index(elem))
+	b.order.Pop()
+	return true
+}
+
+// Len returns the number of distinct elements.
+func (b *Bag[T]) Len() int {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return len(b.counts)
+}
+
+// All returns an iterator over the distinct elements and their counts, in
+// first-insertion order. It iterates over a snapshot taken when iteration
+// starts, so the bag may be modified while iterating.
+func (b *Bag[T]) All() iter.Seq2[T, int] {
+	return func(yield func(T, int) bool) {
+		b.lock.RLock()
+		elems := b.order.Items()
+		counts := make([]int, len(elems))
+		for i, elem := range elems {
+			counts[i] = b.counts[elem]
+		}
+		b.lock.RUnlock()
+		for i, elem := range elems {
+			if !yield(elem, counts[i]) {
+				return
+			}
+		}
+	}
+}
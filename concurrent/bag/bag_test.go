@@ -0,0 +1,62 @@
+package bag
+
+import (
+	"strconv"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBag(t *testing.T) {
+	Convey("test Bag", t, func() {
+		b := NewBag[string]()
+		for _, s := range []string{"b", "a", "b", "c", "b", "a"} {
+			b.Add(s)
+		}
+
+		Convey("test Bag Add counts", func() {
+			So(b.Count("a"), ShouldEqual, 2)
+			So(b.Count("b"), ShouldEqual, 3)
+			So(b.Count("c"), ShouldEqual, 1)
+			So(b.Count("d"), ShouldEqual, 0)
+			So(b.Len(), ShouldEqual, 3)
+		})
+
+		Convey("test Bag Remove", func() {
+			So(b.Remove("d"), ShouldBeFalse)
+			So(b.Remove("b"), ShouldBeTrue)
+			So(b.Count("b"), ShouldEqual, 2)
+			So(b.Len(), ShouldEqual, 3)
+
+			So(b.Remove("a"), ShouldBeTrue)
+			So(b.Remove("a"), ShouldBeTrue)
+			So(b.Count("a"), ShouldEqual, 0)
+			So(b.Remove("a"), ShouldBeFalse)
+			So(b.Len(), ShouldEqual, 2)
+			So(collect(b), ShouldResemble, []string{"b:2", "c:1"})
+
+			// Adding it again places it last.
+			b.Add("a")
+			So(collect(b), ShouldResemble, []string{"b:2", "c:1", "a:1"})
+		})
+
+		Convey("test Bag All order", func() {
+			So(collect(b), ShouldResemble, []string{"b:3", "a:2", "c:1"})
+			var first []string
+			for elem := range b.All() {
+				first = append(first, elem)
+				break
+			}
+			So(first, ShouldResemble, []string{"b"})
+		})
+	})
+}
+
+// collect renders the elements of b as elem:count in iteration order.
+func collect(b *Bag[string]) []string {
+	var items []string
+	for elem, n := range b.All() {
+		items = append(items, elem+":"+strconv.Itoa(n))
+	}
+	return items
+}
@@ -0,0 +1,122 @@
+// Package delay_queue offers a goroutine-safe DelayQueue whose elements only become available once
+// their ready time has arrived.
+package delay_queue
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// DelayQueue is a goroutine-safe queue of elements ordered by ready time, backed by a min-heap.
+type DelayQueue[T any] struct {
+	lock  sync.Mutex
+	items delayHeap[T]
+	// changed is closed and replaced whenever an element is pushed, waking all blocked takers.
+	changed chan struct{}
+	// now returns the current time, it is replaced by tests with a fake clock.
+	now func() time.Time
+}
+
+// NewDelayQueue returns a new, ready-to-use DelayQueue.
+//
+// Example:
+//
+//	dq := delay_queue.NewDelayQueue[string]()
+//	dq.Push("retry", time.Now().Add(time.Second))
+//	v, err := dq.Take(ctx)
+func NewDelayQueue[T any]() *DelayQueue[T] {
+	return &DelayQueue[T]{
+		changed: make(chan struct{}),
+		now:     time.Now,
+	}
+}
+
+// Len returns the number of elements in the queue, ready or not.
+func (dq *DelayQueue[T]) Len() int {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+	return len(dq.items)
+}
+
+// Push inserts item, to become available at readyAt.
+func (dq *DelayQueue[T]) Push(item T, readyAt time.Time) {
+	dq.lock.Lock()
+	heap.Push(&dq.items, delayItem[T]{item: item, readyAt: readyAt})
+	close(dq.changed)
+	dq.changed = make(chan struct{})
+	dq.lock.Unlock()
+}
+
+// PopReady removes and returns the element with the soonest ready time and true if that time is
+// not after now, otherwise it returns a default value and false.
+func (dq *DelayQueue[T]) PopReady(now time.Time) (T, bool) {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+	if len(dq.items) == 0 || dq.items[0].readyAt.After(now) {
+		var v T
+		return v, false
+	}
+	return heap.Pop(&dq.items).(delayItem[T]).item, true
+}
+
+// Take removes and returns the element with the soonest ready time, sleeping until that time
+// arrives or an element is pushed. It returns ctx.Err() if ctx is done first.
+func (dq *DelayQueue[T]) Take(ctx context.Context) (T, error) {
+	for {
+		dq.lock.Lock()
+		changed := dq.changed
+		var timer *time.Timer
+		var wait <-chan time.Time
+		if len(dq.items) > 0 {
+			d := dq.items[0].readyAt.Sub(dq.now())
+			if d <= 0 {
+				item := heap.Pop(&dq.items).(delayItem[T]).item
+				dq.lock.Unlock()
+				return item, nil
+			}
+			timer = time.NewTimer(d)
+			wait = timer.C
+		}
+		dq.lock.Unlock()
+
+		select {
+		case <-wait:
+		case <-changed:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			var v T
+			return v, err
+		}
+	}
+}
+
+type delayItem[T any] struct {
+	item    T
+	readyAt time.Time
+}
+
+// delayHeap implements heap.Interface ordered by ready time.
+type delayHeap[T any] []delayItem[T]
+
+func (h delayHeap[T]) Len() int           { return len(h) }
+func (h delayHeap[T]) Less(i, j int) bool { return h[i].readyAt.Before(h[j].readyAt) }
+func (h delayHeap[T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *delayHeap[T]) Push(x any) {
+	*h = append(*h, x.(delayItem[T]))
+}
+
+func (h *delayHeap[T]) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = delayItem[T]{}
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,80 @@
+package delay_queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDelayQueue(t *testing.T) {
+	Convey("test DelayQueue", t, func() {
+		Convey("test DelayQueue PopReady in time order", func() {
+			clock := time.Unix(1000, 0)
+			dq := NewDelayQueue[string]()
+			dq.Push("c", clock.Add(3*time.Second))
+			dq.Push("a", clock.Add(1*time.Second))
+			dq.Push("b", clock.Add(2*time.Second))
+
+			_, ok := dq.PopReady(clock)
+			So(ok, ShouldBeFalse)
+
+			var got []string
+			for i := 0; i < 4; i++ {
+				clock = clock.Add(time.Second)
+				for v, ok := dq.PopReady(clock); ok; v, ok = dq.PopReady(clock) {
+					got = append(got, v)
+				}
+			}
+			So(got, ShouldResemble, []string{"a", "b", "c"})
+			So(dq.Len(), ShouldEqual, 0)
+		})
+
+		Convey("test DelayQueue Take with a fake clock", func() {
+			clock := time.Unix(1000, 0)
+			dq := NewDelayQueue[string]()
+			dq.now = func() time.Time { return clock }
+			dq.Push("late", clock.Add(time.Hour))
+			dq.Push("due", clock)
+			v, err := dq.Take(context.Background())
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, "due")
+		})
+
+		Convey("test DelayQueue Take sleeps until ready", func() {
+			dq := NewDelayQueue[string]()
+			start := time.Now()
+			dq.Push("b", start.Add(40*time.Millisecond))
+			dq.Push("a", start.Add(20*time.Millisecond))
+			v, err := dq.Take(context.Background())
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, "a")
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 20*time.Millisecond)
+			v, _ = dq.Take(context.Background())
+			So(v, ShouldEqual, "b")
+		})
+
+		Convey("test DelayQueue Take woken by an earlier push", func() {
+			dq := NewDelayQueue[string]()
+			dq.Push("late", time.Now().Add(time.Hour))
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				dq.Push("now", time.Now())
+			}()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			v, err := dq.Take(ctx)
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, "now")
+		})
+
+		Convey("test DelayQueue Take cancelled", func() {
+			dq := NewDelayQueue[string]()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			_, err := dq.Take(ctx)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,157 @@
+/*
+Package deque provides a goroutine-safe double-ended queue backed by the same
+growable ring buffer as package queue. Both ends support O(1) amortized pushes
+and pops, and the buffer shrinks again once it drops to a quarter full.
+*/
+package deque
+
+import "sync"
+
+// minDequeLen is smallest capacity that deque may have.
+// Must be power of 2 for bitwise modulus: x % n == x & (n - 1).
+const minDequeLen = 16
+
+// Deque represents a single instance of the deque data structure.
+type Deque[T any] struct {
+	buf               []T
+	head, tail, count int
+	lock              sync.RWMutex
+}
+
+// NewDeque constructs and returns a new Deque.
+func NewDeque[T any]() *Deque[T] {
+	return &Deque[T]{
+		buf: make([]T, minDequeLen),
+	}
+}
+
+// Len returns the number of elements currently stored in the deque.
+func (d *Deque[T]) Len() int {
+	d.lock.RLock()
+	count := d.count
+	d.lock.RUnlock()
+	return count
+}
+
+// resizes the deque to fit exactly twice its current contents
+// this can result in shrinking if the deque is less than half-full
+func (d *Deque[T]) resize() {
+	newBuf := make([]T, d.count<<1)
+
+	if d.tail > d.head {
+		copy(newBuf, d.buf[d.head:d.tail])
+	} else {
+		n := copy(newBuf, d.buf[d.head:])
+		copy(newBuf[n:], d.buf[:d.tail])
+	}
+
+	d.head = 0
+	d.tail = d.count
+	d.buf = newBuf
+}
+
+// shrink resizes down if buffer 1/4 full.
+func (d *Deque[T]) shrink() {
+	if len(d.buf) > minDequeLen && (d.count<<2) == len(d.buf) {
+		d.resize()
+	}
+}
+
+// PushBack puts an element on the back of the deque.
+func (d *Deque[T]) PushBack(elem T) {
+	d.lock.Lock()
+	if d.count == len(d.buf) {
+		d.resize()
+	}
+
+	d.buf[d.tail] = elem
+	// bitwise modulus
+	d.tail = (d.tail + 1) & (len(d.buf) - 1)
+	d.count++
+	d.lock.Unlock()
+}
+
+// PushFront puts an element on the front of the deque.
+func (d *Deque[T]) PushFront(elem T) {
+	d.lock.Lock()
+	if d.count == len(d.buf) {
+		d.resize()
+	}
+
+	// bitwise modulus
+	d.head = (d.head - 1) & (len(d.buf) - 1)
+	d.buf[d.head] = elem
+	d.count++
+	d.lock.Unlock()
+}
+
+// PopFront removes and returns the element from the front of the deque and
+// true, or a default value and false if the deque is empty.
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	d.lock.Lock()
+	if d.count <= 0 {
+		d.lock.Unlock()
+		return zero, false
+	}
+	ret := d.buf[d.head]
+	d.buf[d.head] = zero
+	// bitwise modulus
+	d.head = (d.head + 1) & (len(d.buf) - 1)
+	d.count--
+	d.shrink()
+	d.lock.Unlock()
+	return ret, true
+}
+
+// PopBack removes and returns the element from the back of the deque and
+// true, or a default value and false if the deque is empty.
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	d.lock.Lock()
+	if d.count <= 0 {
+		d.lock.Unlock()
+		return zero, false
+	}
+	// bitwise modulus
+	d.tail = (d.tail - 1) & (len(d.buf) - 1)
+	ret := d.buf[d.tail]
+	d.buf[d.tail] = zero
+	d.count--
+	d.shrink()
+	d.lock.Unlock()
+	return ret, true
+}
+
+// Front returns the element at the front of the deque and true, or a default
+// value and false if the deque is empty.
+func (d *Deque[T]) Front() (T, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if d.count <= 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[d.head], true
+}
+
+// Back returns the element at the back of the deque and true, or a default
+// value and false if the deque is empty.
+func (d *Deque[T]) Back() (T, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if d.count <= 0 {
+		var zero T
+		return zero, false
+	}
+	// bitwise modulus
+	return d.buf[(d.tail-1)&(len(d.buf)-1)], true
+}
+
+// Clear removes all elements from the deque and releases its buffer.
+func (d *Deque[T]) Clear() {
+	d.lock.Lock()
+	d.buf = make([]T, minDequeLen)
+	d.head, d.tail, d.count = 0, 0, 0
+	d.lock.Unlock()
+}
@@ -0,0 +1,115 @@
+package deque
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// items returns a copy of the contents of d from front to back.
+func items[T any](d *Deque[T]) (out []T) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	for i := 0; i < d.count; i++ {
+		out = append(out, d.buf[(d.head+i)&(len(d.buf)-1)])
+	}
+	return
+}
+
+func TestDeque(t *testing.T) {
+	Convey("test Deque", t, func() {
+		Convey("test Deque empty", func() {
+			d := NewDeque[int]()
+			_, ok := d.PopFront()
+			So(ok, ShouldBeFalse)
+			_, ok = d.PopBack()
+			So(ok, ShouldBeFalse)
+			_, ok = d.Front()
+			So(ok, ShouldBeFalse)
+			_, ok = d.Back()
+			So(ok, ShouldBeFalse)
+			So(d.Len(), ShouldEqual, 0)
+		})
+
+		Convey("test Deque all four ends", func() {
+			d := NewDeque[int]()
+			d.PushBack(1)
+			d.PushFront(0)
+			d.PushBack(2)
+			So(items(d), ShouldResemble, []int{0, 1, 2})
+			v, _ := d.Front()
+			So(v, ShouldEqual, 0)
+			v, _ = d.Back()
+			So(v, ShouldEqual, 2)
+			v, _ = d.PopBack()
+			So(v, ShouldEqual, 2)
+			v, _ = d.PopFront()
+			So(v, ShouldEqual, 0)
+			So(d.Len(), ShouldEqual, 1)
+		})
+
+		Convey("test Deque grows with front pushes", func() {
+			d := NewDeque[int]()
+			n := minDequeLen*4 + 3
+			for i := 0; i < n; i++ {
+				d.PushFront(i)
+			}
+			So(d.Len(), ShouldEqual, n)
+			for i := 0; i < n; i++ {
+				v, ok := d.PopBack()
+				So(ok, ShouldBeTrue)
+				So(v, ShouldEqual, i)
+			}
+			So(len(d.buf), ShouldEqual, minDequeLen)
+		})
+
+		Convey("test Deque mixed ends across resizes and wrap-arounds", func() {
+			d := NewDeque[int]()
+			var want []int
+			for i := 0; i < minDequeLen*3; i++ {
+				if i%2 == 0 {
+					d.PushFront(i)
+					want = append([]int{i}, want...)
+				} else {
+					d.PushBack(i)
+					want = append(want, i)
+				}
+				if i%5 == 4 {
+					v, _ := d.PopFront()
+					So(v, ShouldEqual, want[0])
+					want = want[1:]
+				}
+				if i%7 == 6 {
+					v, _ := d.PopBack()
+					So(v, ShouldEqual, want[len(want)-1])
+					want = want[:len(want)-1]
+				}
+				So(items(d), ShouldResemble, want)
+			}
+			for len(want) > 0 {
+				v, _ := d.PopFront()
+				So(v, ShouldEqual, want[0])
+				want = want[1:]
+				if len(want) > 0 {
+					v, _ = d.PopBack()
+					So(v, ShouldEqual, want[len(want)-1])
+					want = want[:len(want)-1]
+				}
+			}
+			So(d.Len(), ShouldEqual, 0)
+		})
+
+		Convey("test Deque Clear", func() {
+			d := NewDeque[int]()
+			for i := 0; i < minDequeLen*2; i++ {
+				d.PushBack(i)
+			}
+			d.Clear()
+			So(d.Len(), ShouldEqual, 0)
+			So(len(d.buf), ShouldEqual, minDequeLen)
+			d.PushFront(7)
+			v, _ := d.Back()
+			So(v, ShouldEqual, 7)
+		})
+	})
+}
@@ -0,0 +1,80 @@
+// Package fifocache provides a bounded cache which evicts its oldest entries
+// first.
+package fifocache
+
+import (
+	"sync"
+
+	"github.com/eyotang/container/concurrent/queue"
+)
+
+// FIFOCache is a goroutine-safe cache holding at most a fixed number of
+// entries. Keys are kept in a ring-buffer queue in insertion order, and once
+// the cache is full every new key evicts the oldest one. Unlike an LRU cache,
+// reading or updating an entry does not move it.
+type FIFOCache[K comparable, V any] struct {
+	lock     sync.RWMutex
+	values   map[K]V
+	order    *queue.Queue[K]
+	capacity int
+	onEvict  func(K, V)
+}
+
+// NewFIFOCache constructs and returns a new, empty FIFOCache holding at most
+// capacity entries. onEvict, if not nil, is called with every evicted entry,
+// after the cache has been unlocked. This call panics if capacity is not
+// positive.
+func NewFIFOCache[K comparable, V any](capacity int, onEvict func(K, V)) *FIFOCache[K, V] {
+	if capacity <= 0 {
+		panic("fifocache: NewFIFOCache() called with non-positive capacity")
+	}
+	return &FIFOCache[K, V]{
+		values:   make(map[K]V),
+		order:    queue.NewQueueWithCapacity[K](capacity),
+		capacity: capacity,
+		onEvict:  onEvict,
+	}
+}
+
+// Set maps key to val. An existing key keeps its place; a new key is placed
+// last, evicting the oldest entry if the cache is full.
+func (c *FIFOCache[K, V]) Set(key K, val V) {
+	c.lock.Lock()
+	if _, ok := c.values[key]; ok {
+		c.values[key] = val
+		c.lock.Unlock()
+		return
+	}
+	var (
+		evictedKey K
+		evictedVal V
+		evicted    bool
+	)
+	if len(c.values) == c.capacity {
+		evictedKey, _ = c.order.Pop()
+		evictedVal, evicted = c.values[evictedKey], true
+		delete(c.values, evictedKey)
+	}
+	c.values[key] = val
+	c.order.Push(key)
+	c.lock.Unlock()
+	if evicted && c.onEvict != nil {
+		c.onEvict(evictedKey, evictedVal)
+	}
+}
+
+// Get returns the value mapped to key and true, or a default value and false
+// if key is not cached.
+func (c *FIFOCache[K, V]) Get(key K) (V, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	val, ok := c.values[key]
+	return val, ok
+}
+
+// Len returns the number of cached entries.
+func (c *FIFOCache[K, V]) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.values)
+}
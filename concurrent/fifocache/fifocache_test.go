@@ -0,0 +1,61 @@
+package fifocache
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFIFOCache(t *testing.T) {
+	Convey("test FIFOCache", t, func() {
+		var evicted []string
+		c := NewFIFOCache[string, int](3, func(k string, v int) {
+			evicted = append(evicted, fmt.Sprintf("%s=%d", k, v))
+		})
+		c.Set("a", 1)
+		c.Set("b", 2)
+		c.Set("c", 3)
+		So(c.Len(), ShouldEqual, 3)
+		So(evicted, ShouldBeEmpty)
+
+		Convey("test FIFOCache evicts oldest first", func() {
+			c.Set("d", 4)
+			c.Set("e", 5)
+			So(evicted, ShouldResemble, []string{"a=1", "b=2"})
+			So(c.Len(), ShouldEqual, 3)
+			_, ok := c.Get("a")
+			So(ok, ShouldBeFalse)
+			v, ok := c.Get("e")
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, 5)
+		})
+
+		Convey("test FIFOCache update keeps position", func() {
+			c.Set("a", 10)
+			So(evicted, ShouldBeEmpty)
+			v, _ := c.Get("a")
+			So(v, ShouldEqual, 10)
+			c.Set("d", 4)
+			So(evicted, ShouldResemble, []string{"a=10"})
+		})
+
+		Convey("test FIFOCache callback may use the cache", func() {
+			c := NewFIFOCache[int, int](1, nil)
+			var cached []int
+			c.onEvict = func(k, v int) {
+				_, ok := c.Get(k)
+				cached = append(cached, c.Len())
+				So(ok, ShouldBeFalse)
+			}
+			c.Set(1, 1)
+			c.Set(2, 2)
+			So(cached, ShouldResemble, []int{1})
+		})
+
+		Convey("test NewFIFOCache panics", func() {
+			So(func() { NewFIFOCache[int, int](0, nil) }, ShouldPanicWith,
+				"fifocache: NewFIFOCache() called with non-positive capacity")
+		})
+	})
+}
@@ -0,0 +1,52 @@
+package lock_free_queue
+
+import (
+	"runtime"
+	"time"
+)
+
+// BackoffPolicy paces the retries of a goroutine whose CAS on the queue failed because another
+// goroutine got there first. Retrying at once is fastest under light contention, but under heavy
+// contention the retries burn CPU and keep failing each other.
+type BackoffPolicy struct {
+	// Spins is the number of failed attempts retried at once.
+	Spins int
+	// Yields is the number of further failed attempts each followed by runtime.Gosched.
+	Yields int
+	// MinSleep is the sleep after each later failed attempt, doubling every time up to MaxSleep.
+	MinSleep, MaxSleep time.Duration
+}
+
+// DefaultBackoffPolicy suits queues shared by many more goroutines than there are CPUs.
+var DefaultBackoffPolicy = BackoffPolicy{
+	Spins:    4,
+	Yields:   16,
+	MinSleep: time.Microsecond,
+	MaxSleep: time.Millisecond,
+}
+
+// NewQueueWithBackoff returns a new LockfreeQueue which paces CAS retries in Push and Pop by
+// policy, trading some latency for less CPU under contention. Queues created by NewQueue retry at
+// once.
+func NewQueueWithBackoff[T any](policy BackoffPolicy) *LockFreeQueue[T] {
+	queue := NewQueue[T]()
+	queue.backoff = &policy
+	return queue
+}
+
+// wait is called after the given number of consecutive failed attempts, counting from 1. A nil
+// policy never waits.
+func (p *BackoffPolicy) wait(failures int) {
+	if p == nil || failures <= p.Spins {
+		return
+	}
+	if failures <= p.Spins+p.Yields {
+		runtime.Gosched()
+		return
+	}
+	sleep := p.MinSleep
+	for n := failures - p.Spins - p.Yields; n > 1 && sleep < p.MaxSleep; n-- {
+		sleep <<= 1
+	}
+	time.Sleep(min(sleep, p.MaxSleep))
+}
@@ -0,0 +1,83 @@
+package lock_free_queue
+
+import (
+	"runtime"
+	"runtime/metrics"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestBackoffQueue(t *testing.T) {
+	q := NewQueueWithBackoff[int](BackoffPolicy{Spins: 1, Yields: 1, MinSleep: 1, MaxSleep: 100})
+	const n = 10000
+	var wg sync.WaitGroup
+	results := make([][]int, kGoRoutineNum)
+	wg.Add(2 * kGoRoutineNum)
+	for i := 0; i != kGoRoutineNum; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j != n; j++ {
+				q.Push(j)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j != n; j++ {
+				if v, ok := q.Pop(); ok {
+					results[i] = append(results[i], v)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var resultBuf []int
+	for i := range results {
+		resultBuf = append(resultBuf, results[i]...)
+	}
+	for v, ok := q.Pop(); ok; v, ok = q.Pop() {
+		resultBuf = append(resultBuf, v)
+	}
+	if len(resultBuf) != n*kGoRoutineNum {
+		t.Fatal("Invalid length:", len(resultBuf))
+	}
+	sort.Ints(resultBuf)
+	for i := range resultBuf {
+		if resultBuf[i] != i/kGoRoutineNum {
+			t.Fatal("Invalid result:", i, resultBuf[i])
+		}
+	}
+}
+
+// benchmarkContended pushes and pops from many more goroutines than there are CPUs, reporting
+// the CPU time spent per operation next to the wall time. The CPU metrics are only brought up
+// to date by a GC, so one is forced around the measured loop.
+func benchmarkContended(b *testing.B, q *LockFreeQueue[int]) {
+	sample := []metrics.Sample{{Name: "/cpu/classes/user:cpu-seconds"}}
+	runtime.GC()
+	metrics.Read(sample)
+	before := sample[0].Value.Float64()
+	b.ResetTimer()
+
+	b.SetParallelism(16)
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			q.Push(i)
+			q.Pop()
+		}
+	})
+
+	b.StopTimer()
+	runtime.GC()
+	metrics.Read(sample)
+	b.ReportMetric((sample[0].Value.Float64()-before)*1e9/float64(b.N), "cpu-ns/op")
+}
+
+func BenchmarkContended_NoBackoff(b *testing.B) {
+	benchmarkContended(b, NewQueue[int]())
+}
+
+func BenchmarkContended_Backoff(b *testing.B) {
+	benchmarkContended(b, NewQueueWithBackoff[int](DefaultBackoffPolicy))
+}
@@ -0,0 +1,40 @@
+package lock_free_queue
+
+// BoundedLockFreeQueue is a LockFreeQueue which rejects pushes once it holds max elements.
+type BoundedLockFreeQueue[T any] struct {
+	LockFreeQueue[T]
+	max int64
+}
+
+// NewBoundedLockFreeQueue returns a new, ready-to-use BoundedLockFreeQueue holding at most max elements.
+//
+// Example:
+//
+//	lfq := queue.NewBoundedLockFreeQueue[int](1024)
+//	if !lfq.Push(100) {
+//		// queue is full
+//	}
+func NewBoundedLockFreeQueue[T any](max int64) *BoundedLockFreeQueue[T] {
+	queue := &BoundedLockFreeQueue[T]{max: max}
+	queue.init()
+	return queue
+}
+
+// Push inserts an element to the back of the queue and returns true, or returns false if the queue is full.
+// It panics if the queue has been closed.
+// A slot is reserved by a CAS on the length counter before the element is linked in, so concurrent
+// producers can never push the length past max.
+func (queue *BoundedLockFreeQueue[T]) Push(val T) bool {
+	for {
+		n := queue.length.Load()
+		if n >= queue.max {
+			return false
+		}
+		if queue.length.CompareAndSwap(n, n+1) {
+			break
+		}
+	}
+	queue.checkClosed()
+	queue.enqueue(val)
+	return true
+}
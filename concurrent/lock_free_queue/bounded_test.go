@@ -0,0 +1,61 @@
+package lock_free_queue
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBoundedLockFreeQueue(t *testing.T) {
+	const max = 100
+	q := NewBoundedLockFreeQueue[int](max)
+
+	var (
+		pushWg   sync.WaitGroup
+		accepted atomic.Int64
+		stop     = make(chan struct{})
+		watchWg  sync.WaitGroup
+	)
+	watchWg.Add(1)
+	go func() {
+		defer watchWg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				if n := q.Len(); n > max {
+					t.Error("Len exceeded max:", n)
+				}
+			}
+		}
+	}()
+
+	pushWg.Add(kGoRoutineNum)
+	for i := 0; i != kGoRoutineNum; i++ {
+		go func() {
+			defer pushWg.Done()
+			for j := 0; j != max; j++ {
+				if q.Push(j) {
+					accepted.Add(1)
+				}
+			}
+		}()
+	}
+	pushWg.Wait()
+	close(stop)
+	watchWg.Wait()
+
+	if accepted.Load() != max || q.Len() != max {
+		t.Error("Invalid result:", accepted.Load(), q.Len())
+	}
+	if q.Push(0) {
+		t.Error("Push should fail on a full queue")
+	}
+	if _, ok := q.Pop(); !ok {
+		t.Error("Should never be empty!")
+	}
+	if !q.Push(0) {
+		t.Error("Push should succeed after Pop")
+	}
+}
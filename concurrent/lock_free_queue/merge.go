@@ -0,0 +1,48 @@
+package lock_free_queue
+
+import "sync/atomic"
+
+// MergedView consumes from several LockFreeQueues as if they were one.
+type MergedView[T any] struct {
+	sources []*LockFreeQueue[T]
+	next    atomic.Uint64
+}
+
+// Merge returns a MergedView popping from sources in rotation. The rotation state is a single
+// atomic counter, so Pop stays lock-free.
+//
+// Example:
+//
+//	mv := queue.Merge(lfq1, lfq2, lfq3)
+//	for v, ok := mv.Pop(); ok; v, ok = mv.Pop() {
+//		handle(v)
+//	}
+func Merge[T any](sources ...*LockFreeQueue[T]) *MergedView[T] {
+	return &MergedView[T]{sources: sources}
+}
+
+// Pop returns (and removes) an element from the next non-empty source in rotation and true,
+// otherwise it returns a default value and false if all sources are empty. Each call starts
+// from the source after the one the previous call started from, so no source starves.
+func (mv *MergedView[T]) Pop() (T, bool) {
+	n := uint64(len(mv.sources))
+	if n > 0 {
+		start := mv.next.Add(1) - 1
+		for i := uint64(0); i != n; i++ {
+			if v, ok := mv.sources[(start+i)%n].Pop(); ok {
+				return v, true
+			}
+		}
+	}
+	var v T
+	return v, false
+}
+
+// Len returns the total number of elements across all sources.
+func (mv *MergedView[T]) Len() int64 {
+	var total int64
+	for _, source := range mv.sources {
+		total += source.Len()
+	}
+	return total
+}
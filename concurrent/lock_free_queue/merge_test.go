@@ -0,0 +1,46 @@
+package lock_free_queue
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	sources := []*LockFreeQueue[int]{NewQueue[int](), NewQueue[int](), NewQueue[int]()}
+	for i, source := range sources {
+		for j := 0; j != 100; j++ {
+			source.Push(i*100 + j)
+		}
+	}
+	mv := Merge(sources...)
+	if mv.Len() != 300 {
+		t.Error("Invalid length:", mv.Len())
+	}
+
+	var resultBuf []int
+	last := []int{-1, -1, -1}
+	for v, ok := mv.Pop(); ok; v, ok = mv.Pop() {
+		// Each source must still be drained in FIFO order.
+		if v <= last[v/100] {
+			t.Error("Out of order:", v, last[v/100])
+		}
+		last[v/100] = v
+		resultBuf = append(resultBuf, v)
+	}
+	if len(resultBuf) != 300 {
+		t.Fatal("Invalid length:", len(resultBuf))
+	}
+	// Rotation interleaves the sources.
+	if resultBuf[0]/100 == resultBuf[1]/100 {
+		t.Error("Sources were not rotated:", resultBuf[:3])
+	}
+	sort.Ints(resultBuf)
+	for i := range resultBuf {
+		if resultBuf[i] != i {
+			t.Error("Invalid result:", i, resultBuf[i])
+		}
+	}
+	if _, ok := Merge[int]().Pop(); ok {
+		t.Error("Empty merge should be empty!")
+	}
+}
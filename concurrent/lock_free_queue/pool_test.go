@@ -0,0 +1,91 @@
+package lock_free_queue
+
+import (
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestPooledQueue(t *testing.T) {
+	const n = 100000
+	q := NewPooledQueue[int]()
+
+	// Sequential reuse must not leak old values into new nodes.
+	for round := 0; round != 3; round++ {
+		for i := 0; i != 100; i++ {
+			q.Push(round*100 + i)
+		}
+		for i := 0; i != 100; i++ {
+			if v, ok := q.Pop(); !ok || v != round*100+i {
+				t.Error("Invalid result:", round, i, v, ok)
+			}
+		}
+	}
+
+	var pushWg, popWg sync.WaitGroup
+	results := make([][]int, kGoRoutineNum)
+	done := make(chan struct{})
+	pushWg.Add(kGoRoutineNum)
+	popWg.Add(kGoRoutineNum)
+	for i := 0; i != kGoRoutineNum; i++ {
+		go func() {
+			defer pushWg.Done()
+			for j := 0; j != n; j++ {
+				q.Push(j)
+			}
+		}()
+		go func(i int) {
+			defer popWg.Done()
+			for {
+				v, ok := q.Pop()
+				if ok {
+					results[i] = append(results[i], v)
+					continue
+				}
+				select {
+				case <-done:
+					return
+				default:
+				}
+			}
+		}(i)
+	}
+	pushWg.Wait()
+	close(done)
+	popWg.Wait()
+
+	var resultBuf []int
+	for i := range results {
+		resultBuf = append(resultBuf, results[i]...)
+	}
+	for v, ok := q.Pop(); ok; v, ok = q.Pop() {
+		resultBuf = append(resultBuf, v)
+	}
+	sort.Ints(resultBuf)
+	if len(resultBuf) != n*kGoRoutineNum {
+		t.Fatal("Invalid length:", len(resultBuf))
+	}
+	for i := 0; i != n; i++ {
+		for j := 0; j != kGoRoutineNum; j++ {
+			if resultBuf[(i*kGoRoutineNum)+j] != i {
+				t.Fatal("Invalid result:", i, j, resultBuf[(i*kGoRoutineNum)+j])
+			}
+		}
+	}
+}
+
+func benchmarkPushPop(b *testing.B, q *LockFreeQueue[int]) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		q.Push(i)
+		q.Pop()
+	}
+}
+
+func BenchmarkQueue_PushPop(b *testing.B) {
+	benchmarkPushPop(b, NewQueue[int]())
+}
+
+func BenchmarkPooledQueue_PushPop(b *testing.B) {
+	benchmarkPushPop(b, NewPooledQueue[int]())
+}
@@ -0,0 +1,167 @@
+package lock_free_queue
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// agedEntry is an element of a PriorityLockFreeQueue along with the time it was pushed, in
+// nanoseconds since the Unix epoch, or zero if aging was off at the time.
+type agedEntry[T any] struct {
+	val      T
+	enqueued int64
+}
+
+// PriorityLockFreeQueue routes elements into a fixed number of priority levels, each backed by
+// its own LockFreeQueue. Pop always serves the highest non-empty level, so a steady stream into
+// a high level starves the levels below it indefinitely; lower levels only drain once every
+// level above them is empty. Within a level elements stay FIFO, but across levels they do not.
+//
+// A queue built by NewStrictPriorityQueue instead funnels every level through a single
+// LockFreeQueue, so elements come out in exact FIFO order whatever their level, at the cost of
+// priorities being ignored.
+//
+// SetAging lets waiting elements gain priority over time, so that lower levels are not starved.
+type PriorityLockFreeQueue[T any] struct {
+	levels []*LockFreeQueue[agedEntry[T]]
+	// numLevels is the number of levels accepted by Push. It equals len(levels), except in strict
+	// mode where all of them share levels[0].
+	numLevels int
+	strict    bool
+	// aging holds the Float64bits of the boost set by SetAging, zero means off.
+	aging atomic.Uint64
+	// now returns the current time, and is only replaced by tests.
+	now func() time.Time
+}
+
+// NewPriorityQueue returns an empty queue with levels priority levels, numbered 0 (lowest) to
+// levels-1 (highest). It panics if levels is not positive.
+func NewPriorityQueue[T any](levels int) *PriorityLockFreeQueue[T] {
+	if levels <= 0 {
+		panic(fmt.Sprintf("queue: NewPriorityQueue() called with levels %d", levels))
+	}
+	pq := &PriorityLockFreeQueue[T]{
+		levels:    make([]*LockFreeQueue[agedEntry[T]], levels),
+		numLevels: levels,
+		now:       time.Now,
+	}
+	for i := range pq.levels {
+		pq.levels[i] = NewQueue[agedEntry[T]]()
+	}
+	return pq
+}
+
+// NewStrictPriorityQueue returns an empty queue accepting the same levels as NewPriorityQueue,
+// but in strict mode: all levels share one LockFreeQueue, so Pop returns elements in the exact
+// order they were pushed, regardless of level. It trades priority ordering, and the throughput
+// of spreading contention over several queues, for FIFO. It panics if levels is not positive.
+func NewStrictPriorityQueue[T any](levels int) *PriorityLockFreeQueue[T] {
+	if levels <= 0 {
+		panic(fmt.Sprintf("queue: NewStrictPriorityQueue() called with levels %d", levels))
+	}
+	return &PriorityLockFreeQueue[T]{
+		levels:    []*LockFreeQueue[agedEntry[T]]{NewQueue[agedEntry[T]]()},
+		numLevels: levels,
+		strict:    true,
+		now:       time.Now,
+	}
+}
+
+// Push puts the given value at the tail of the given priority level, or of the single shared
+// queue in strict mode. It panics if level is out of range.
+func (pq *PriorityLockFreeQueue[T]) Push(val T, level int) {
+	if level < 0 || level >= pq.numLevels {
+		panic(fmt.Sprintf("queue: Push() called with level %d of %d", level, pq.numLevels))
+	}
+	if pq.strict {
+		level = 0
+	}
+	e := agedEntry[T]{val: val}
+	if pq.aging.Load() != 0 {
+		e.enqueued = pq.now().UnixNano()
+	}
+	pq.levels[level].Push(e)
+}
+
+// SetAging makes an element's effective priority grow by boostPerSecond levels for every second
+// it has waited, so that a low-priority element eventually overtakes fresh elements of higher
+// levels. Only elements pushed while aging is on are timestamped; the others never age. A
+// boostPerSecond of zero, the default, turns aging off. It panics if boostPerSecond is negative
+// or not finite.
+func (pq *PriorityLockFreeQueue[T]) SetAging(boostPerSecond float64) {
+	if boostPerSecond < 0 || math.IsInf(boostPerSecond, 0) || math.IsNaN(boostPerSecond) {
+		panic(fmt.Sprintf("queue: SetAging() called with boost %v", boostPerSecond))
+	}
+	pq.aging.Store(math.Float64bits(boostPerSecond))
+}
+
+// Pop returns (and removes) an element from the highest non-empty level and true, otherwise it
+// returns a default value and false if every level is empty. Levels are scanned one after
+// another, so an element pushed into a higher level while the scan is past it is only seen by
+// the next call.
+//
+// With aging on, Pop instead compares the front element of every level, the longest waiting one
+// there, by its level plus the boost it has accumulated, and pops from the level with the highest
+// score, preferring the higher level on ties. The fronts are only peeked at, so under concurrent
+// Pops the element removed may be a later one of the chosen level.
+func (pq *PriorityLockFreeQueue[T]) Pop() (T, bool) {
+	if boost := math.Float64frombits(pq.aging.Load()); boost != 0 {
+		return pq.popAged(boost)
+	}
+	for i := len(pq.levels) - 1; i >= 0; i-- {
+		if e, ok := pq.levels[i].Pop(); ok {
+			return e.val, true
+		}
+	}
+	var v T
+	return v, false
+}
+
+// popAged implements Pop with aging on.
+func (pq *PriorityLockFreeQueue[T]) popAged(boost float64) (T, bool) {
+	for {
+		now := pq.now().UnixNano()
+		best, bestScore := -1, math.Inf(-1)
+		for i := len(pq.levels) - 1; i >= 0; i-- {
+			pq.levels[i].ForEach(func(e agedEntry[T]) bool {
+				score := float64(i)
+				if e.enqueued != 0 {
+					score += boost * float64(now-e.enqueued) / float64(time.Second)
+				}
+				if score > bestScore {
+					best, bestScore = i, score
+				}
+				return false
+			})
+		}
+		if best < 0 {
+			var v T
+			return v, false
+		}
+		if e, ok := pq.levels[best].Pop(); ok {
+			return e.val, true
+		}
+		// The level was drained meanwhile, score the fronts again.
+	}
+}
+
+// Len returns the total number of elements across all levels.
+func (pq *PriorityLockFreeQueue[T]) Len() int64 {
+	var total int64
+	for _, level := range pq.levels {
+		total += level.Len()
+	}
+	return total
+}
+
+// Levels returns the number of priority levels.
+func (pq *PriorityLockFreeQueue[T]) Levels() int {
+	return pq.numLevels
+}
+
+// Strict reports whether the queue was built by NewStrictPriorityQueue.
+func (pq *PriorityLockFreeQueue[T]) Strict() bool {
+	return pq.strict
+}
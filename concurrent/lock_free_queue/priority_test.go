@@ -0,0 +1,164 @@
+package lock_free_queue
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	const kLevels = 4
+	pq := NewPriorityQueue[int](kLevels)
+	if _, ok := pq.Pop(); ok {
+		t.Error("Pop on empty queue should fail")
+	}
+
+	// Each value encodes its level as value%kLevels.
+	var pushers sync.WaitGroup
+	pushers.Add(kGoRoutineNum)
+	for i := 0; i != kGoRoutineNum; i++ {
+		go func(i int) {
+			defer pushers.Done()
+			for j := 0; j != kPushingNum; j++ {
+				v := i*kPushingNum + j
+				pq.Push(v, v%kLevels)
+			}
+		}(i)
+	}
+	pushers.Wait()
+	if pq.Len() != kBufSz {
+		t.Error("Invalid length:", pq.Len())
+	}
+
+	seen := make([]bool, kBufSz)
+	lastLevel := kLevels - 1
+	for v, ok := pq.Pop(); ok; v, ok = pq.Pop() {
+		if v%kLevels > lastLevel {
+			t.Fatal("Higher level popped after lower one:", v%kLevels, lastLevel)
+		}
+		lastLevel = v % kLevels
+		if seen[v] {
+			t.Error("Duplicated result:", v)
+		}
+		seen[v] = true
+	}
+	for i := range seen {
+		if !seen[i] {
+			t.Error("Missing result:", i)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Push to out of range level should panic")
+		}
+	}()
+	pq.Push(0, kLevels)
+}
+
+func TestPriorityQueueStrictMode(t *testing.T) {
+	const kLevels = 4
+	relaxed, strict := NewPriorityQueue[int](kLevels), NewStrictPriorityQueue[int](kLevels)
+	if relaxed.Strict() || !strict.Strict() || strict.Levels() != kLevels {
+		t.Error("Invalid mode:", relaxed.Strict(), strict.Strict(), strict.Levels())
+	}
+	for v := 0; v != 100; v++ {
+		relaxed.Push(v, v%kLevels)
+		strict.Push(v, v%kLevels)
+	}
+	if strict.Len() != 100 {
+		t.Error("Invalid length:", strict.Len())
+	}
+	for i := 0; i != 100; i++ {
+		if v, ok := strict.Pop(); !ok || v != i {
+			t.Fatal("Invalid result:", i, v, ok)
+		}
+	}
+	// Relaxed mode serves level 3 first, so the first value pushed is not the first popped.
+	if v, _ := relaxed.Pop(); v == 0 {
+		t.Error("Relaxed mode should not keep FIFO across levels")
+	}
+
+	// Under concurrent producers strict mode still keeps each producer's values in order.
+	var pushers sync.WaitGroup
+	pushers.Add(kGoRoutineNum)
+	for i := 0; i != kGoRoutineNum; i++ {
+		go func(i int) {
+			defer pushers.Done()
+			for j := 0; j != kPushingNum; j++ {
+				strict.Push(i*kPushingNum+j, j%kLevels)
+			}
+		}(i)
+	}
+	pushers.Wait()
+	last := make([]int, kGoRoutineNum)
+	for i := range last {
+		last[i] = -1
+	}
+	n := 0
+	for v, ok := strict.Pop(); ok; v, ok = strict.Pop() {
+		producer, j := v/kPushingNum, v%kPushingNum
+		if j <= last[producer] {
+			t.Fatal("Out of order result:", producer, last[producer], j)
+		}
+		last[producer] = j
+		n++
+	}
+	if n != kBufSz {
+		t.Error("Invalid length:", n)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Push to out of range level should panic")
+		}
+	}()
+	strict.Push(0, kLevels)
+}
+
+func TestPriorityQueueAging(t *testing.T) {
+	clock := time.Unix(1000, 0)
+	newQueue := func(boost float64) *PriorityLockFreeQueue[string] {
+		pq := NewPriorityQueue[string](4)
+		pq.now = func() time.Time { return clock }
+		pq.SetAging(boost)
+		// The old element waits at the lowest level while fresh ones keep arriving at the highest.
+		pq.Push("old", 0)
+		clock = clock.Add(2 * time.Second)
+		pq.Push("fresh1", 3)
+		return pq
+	}
+
+	pq := newQueue(0)
+	clock = clock.Add(time.Hour)
+	pq.Push("fresh2", 3)
+	for _, want := range []string{"fresh1", "fresh2", "old"} {
+		if v, ok := pq.Pop(); !ok || v != want {
+			t.Error("Invalid result without aging:", want, v, ok)
+		}
+	}
+
+	// A boost of 0.5 levels per second needs the old element to wait over 6s to beat level 3.
+	pq = newQueue(0.5)
+	if v, ok := pq.Pop(); !ok || v != "fresh1" {
+		t.Error("Invalid result:", v, ok)
+	}
+	clock = clock.Add(6 * time.Second)
+	pq.Push("fresh2", 3)
+	if v, ok := pq.Pop(); !ok || v != "old" {
+		t.Error("Old element should overtake fresh ones:", v, ok)
+	}
+	if v, ok := pq.Pop(); !ok || v != "fresh2" {
+		t.Error("Invalid result:", v, ok)
+	}
+	if _, ok := pq.Pop(); ok {
+		t.Error("Pop on empty queue should fail")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Negative boost should panic")
+		}
+	}()
+	pq.SetAging(-1)
+}
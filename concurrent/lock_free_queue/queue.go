@@ -22,6 +22,8 @@
 package lock_free_queue
 
 import (
+	"math"
+	"sync"
 	"sync/atomic"
 	"unsafe"
 )
@@ -29,9 +31,22 @@ import (
 // LockFreeQueue is a goroutine-safe LockFreeQueue implementation.
 // The overall performance of LockFreeQueue is much better than List+Mutex(standard package).
 type LockFreeQueue[T any] struct {
-	head  unsafe.Pointer
-	tail  unsafe.Pointer
-	dummy qNode[T]
+	head   unsafe.Pointer
+	tail   unsafe.Pointer
+	dummy  qNode[T]
+	ready  chan struct{}
+	length atomic.Int64
+	closed atomic.Bool
+	// readers counts ForEach and CompareAndPop calls, which read values of nodes they have not
+	// popped, see release.
+	readers atomic.Int64
+	// backoff paces CAS retries, see NewQueueWithBackoff. nil retries at once.
+	backoff *BackoffPolicy
+
+	// Node recycling state, only used by queues created by NewPooledQueue.
+	pool    *sync.Pool
+	active  atomic.Int64
+	retired unsafe.Pointer
 }
 
 // NewQueue is the only way to get a new, ready-to-use LockfreeQueue.
@@ -43,23 +58,81 @@ type LockFreeQueue[T any] struct {
 //	v, ok := lfq.Pop()
 func NewQueue[T any]() *LockFreeQueue[T] {
 	var queue LockFreeQueue[T]
+	queue.init()
+	return &queue
+}
+
+// NewQueueFromSlice returns a new LockfreeQueue holding items, with items[0] at the front.
+// Construction is single-threaded, so the chain is linked directly instead of pushing each
+// element with a CAS.
+func NewQueueFromSlice[T any](items []T) *LockFreeQueue[T] {
+	queue := NewQueue[T]()
+	last := &queue.dummy
+	for _, item := range items {
+		node := &qNode[T]{val: item}
+		last.next = unsafe.Pointer(node)
+		last = node
+	}
+	queue.tail = unsafe.Pointer(last)
+	queue.length.Store(int64(len(items)))
+	return queue
+}
+
+// init points head and tail at the dummy node. The queue must not be copied afterwards.
+func (queue *LockFreeQueue[T]) init() {
 	queue.head = unsafe.Pointer(&queue.dummy)
 	queue.tail = queue.head
-	return &queue
+}
+
+// NewNotifyQueue returns a new LockfreeQueue which also signals Ready() on every Push.
+//
+// Notifications are coalesced: the channel holds at most one pending signal, so a single
+// wake may cover several pushes. Workers should therefore drain the queue with Pop until
+// it reports empty after each receive.
+//
+// Example:
+//
+//	lfq := queue.NewNotifyQueue[int]()
+//	for range lfq.Ready() {
+//		for v, ok := lfq.Pop(); ok; v, ok = lfq.Pop() {
+//			handle(v)
+//		}
+//	}
+func NewNotifyQueue[T any]() *LockFreeQueue[T] {
+	queue := NewQueue[T]()
+	queue.ready = make(chan struct{}, 1)
+	return queue
+}
+
+// Ready returns the channel signalled by Push on queues created by NewNotifyQueue.
+// It returns nil, which blocks forever on receive, for queues created by NewQueue.
+func (queue *LockFreeQueue[T]) Ready() <-chan struct{} {
+	return queue.ready
 }
 
 // Pop returns (and removes) an element from the front of the queue and true if the queue is not empty,
 // otherwise it returns a default value and false if the queue is empty.
 // It performs about 100% better than list.List.Front() and list.List.Pop() with sync.Mutex.
 func (queue *LockFreeQueue[T]) Pop() (T, bool) {
-	for {
+	if queue.pool != nil {
+		queue.active.Add(1)
+		defer queue.quiesce()
+	}
+	for failures := 1; ; failures++ {
 		h := atomic.LoadPointer(&queue.head)
 		rh := (*qNode[T])(h)
 		n := (*qNode[T])(atomic.LoadPointer(&rh.next))
 		if n != nil {
-			if atomic.CompareAndSwapPointer(&queue.head, h, rh.next) {
-				return n.val, true
+			if atomic.CompareAndSwapPointer(&queue.head, h, unsafe.Pointer(n)) {
+				queue.length.Add(-1)
+				if queue.pool != nil {
+					queue.retire(rh)
+				}
+				v := n.val
+				queue.release(n)
+				return v, true
 			} else {
+				queue.backoff.wait(failures)
 				continue
 			}
 		} else {
@@ -69,20 +142,262 @@ func (queue *LockFreeQueue[T]) Pop() (T, bool) {
 	}
 }
 
-// Push inserts an element to the back of the queue.
+// CompareAndPop removes the element at the front of the queue only if it equals expected,
+// returning it and true. It returns a default value and false, leaving the queue untouched, if
+// the queue is empty or its front element differs.
+func CompareAndPop[T comparable](queue *LockFreeQueue[T], expected T) (T, bool) {
+	if queue.pool != nil {
+		queue.active.Add(1)
+		defer queue.quiesce()
+	}
+	queue.readers.Add(1)
+	for failures := 1; ; failures++ {
+		h := atomic.LoadPointer(&queue.head)
+		rh := (*qNode[T])(h)
+		n := (*qNode[T])(atomic.LoadPointer(&rh.next))
+		if n == nil || n.val != expected {
+			queue.readers.Add(-1)
+			var v T
+			return v, false
+		}
+		if atomic.CompareAndSwapPointer(&queue.head, h, unsafe.Pointer(n)) {
+			queue.readers.Add(-1)
+			queue.length.Add(-1)
+			if queue.pool != nil {
+				queue.retire(rh)
+			}
+			v := n.val
+			queue.release(n)
+			return v, true
+		}
+		queue.backoff.wait(failures)
+	}
+}
+
+// TransferTo moves up to max elements from the front of the queue to the back of dst, keeping
+// their order, and returns the number moved. The elements leave the queue with a single CAS on
+// its head and arrive in dst with a single CAS on its tail, so other goroutines see them move as
+// a batch. Only the values are moved: they are copied into fresh nodes, since goroutines holding
+// a stale head or tail may still follow the old ones. It panics if dst has been closed.
+func (queue *LockFreeQueue[T]) TransferTo(dst *LockFreeQueue[T], max int) int {
+	reserve := min(int64(max), queue.length.Load())
+	if reserve <= 0 {
+		return 0
+	}
+	// Reserve the slots in dst before checking closed, as Push does.
+	dst.length.Add(reserve)
+	if dst.closed.Load() {
+		dst.length.Add(-reserve)
+		panic("queue: TransferTo() called on closed queue")
+	}
+	vals := queue.detach(int(reserve))
+	dst.length.Add(int64(len(vals)) - reserve)
+	if len(vals) > 0 {
+		dst.enqueueAll(vals)
+	}
+	return len(vals)
+}
+
+// PopAll removes and returns every element in the queue, front first. The elements are unlinked
+// with a single CAS on the head, as in TransferTo, rather than popped one by one. It is meant for
+// shutdown and other draining scenarios: elements pushed concurrently with the call may or may not
+// be captured, and the queue may be non-empty again by the time PopAll returns.
+func (queue *LockFreeQueue[T]) PopAll() []T {
+	vals := queue.detach(math.MaxInt)
+	if vals == nil {
+		return []T{}
+	}
+	return vals
+}
+
+// detach unlinks up to max elements from the front of the queue with a single CAS on the head
+// and returns their values.
+func (queue *LockFreeQueue[T]) detach(max int) []T {
+	if queue.pool != nil {
+		queue.active.Add(1)
+		defer queue.quiesce()
+	}
+	for failures := 1; ; failures++ {
+		h := atomic.LoadPointer(&queue.head)
+		rh := (*qNode[T])(h)
+		last, k := rh, 0
+		for ; k < max; k++ {
+			next := atomic.LoadPointer(&last.next)
+			if next == nil {
+				break
+			}
+			last = (*qNode[T])(next)
+		}
+		if k == 0 {
+			return nil
+		}
+		if !atomic.CompareAndSwapPointer(&queue.head, h, unsafe.Pointer(last)) {
+			queue.backoff.wait(failures)
+			continue
+		}
+		queue.length.Add(-int64(k))
+		// As in Pop, the last node unlinked becomes the dummy node, and the others are retired.
+		vals := make([]T, 0, k)
+		for node := rh; node != last; {
+			next := (*qNode[T])(atomic.LoadPointer(&node.next))
+			if queue.pool != nil {
+				queue.retire(node)
+			}
+			vals = append(vals, next.val)
+			node = next
+		}
+		queue.release(last)
+		return vals
+	}
+}
+
+// release clears the value of n, which has just become the dummy node by being popped, so that
+// the popped value can be collected. Only the pop that unlinked n writes its value, but ForEach
+// and CompareAndPop read values before any CAS, so n may still be looked at by a reader holding
+// a stale head. The value is left in place while any reader is in flight; a reader starting
+// after the check can only load the head after n was unlinked, and never reads n.val. A value
+// left behind is collected with n once the next pop moves past it.
+func (queue *LockFreeQueue[T]) release(n *qNode[T]) {
+	if queue.readers.Load() == 0 {
+		var zero T
+		n.val = zero
+	}
+}
+
+// PopOrDone behaves like Pop, and additionally reports done once the queue has been closed and
+// fully drained, so that consumers can leave their loops.
+//
+// Example:
+//
+//	for {
+//		v, ok, done := lfq.PopOrDone()
+//		if done {
+//			break
+//		}
+//		if ok {
+//			handle(v)
+//		}
+//	}
+func (queue *LockFreeQueue[T]) PopOrDone() (val T, ok bool, done bool) {
+	if val, ok = queue.Pop(); ok {
+		return
+	}
+	// Push bumps length before checking closed, so a zero length observed after closed can
+	// not be followed by another element.
+	done = queue.closed.Load() && queue.length.Load() == 0
+	return
+}
+
+// Close marks the queue closed. Elements already pushed can still be popped, but pushing after
+// Close panics. Close also signals Ready() so that waiting workers notice the shutdown.
+func (queue *LockFreeQueue[T]) Close() {
+	queue.closed.Store(true)
+	queue.notify()
+}
+
+// Push inserts an element to the back of the queue. It panics if the queue has been closed.
 // It performs exactly the same as list.List.PushBack() with sync.Mutex.
 func (queue *LockFreeQueue[T]) Push(val T) {
-	node := unsafe.Pointer(&qNode[T]{val: val})
-	for {
+	queue.length.Add(1)
+	queue.checkClosed()
+	queue.enqueue(val)
+}
+
+// checkClosed releases the slot reserved in the length counter and panics if the queue has been closed.
+func (queue *LockFreeQueue[T]) checkClosed() {
+	if queue.closed.Load() {
+		queue.length.Add(-1)
+		panic("queue: Push() called on closed queue")
+	}
+}
+
+// Len returns the number of elements in the queue. The counter is bumped before an element is
+// linked in, so Len may briefly include an element that Pop cannot return yet.
+func (queue *LockFreeQueue[T]) Len() int64 {
+	return queue.length.Load()
+}
+
+// ApproxLen counts the elements by walking the chain from head, without the length counter.
+// It is O(n) and racy: under concurrent Push and Pop the result is only an estimate, as nodes
+// are counted while the chain keeps changing. It is meant for diagnostics, not hot paths.
+func (queue *LockFreeQueue[T]) ApproxLen() int {
+	if queue.pool != nil {
+		// Keep the nodes being walked from being recycled.
+		queue.active.Add(1)
+		defer queue.quiesce()
+	}
+	n := 0
+	rh := (*qNode[T])(atomic.LoadPointer(&queue.head))
+	for next := atomic.LoadPointer(&rh.next); next != nil; next = atomic.LoadPointer(&rh.next) {
+		rh = (*qNode[T])(next)
+		n++
+	}
+	return n
+}
+
+// ForEach calls fn for each element from front to back, starting at head.next, and stops early
+// if fn returns false. The queue is not modified. It is only exact on a quiescent queue; under
+// concurrent Push or Pop it is best-effort and may skip elements that were popped meanwhile or
+// visit elements pushed meanwhile.
+func (queue *LockFreeQueue[T]) ForEach(fn func(T) bool) {
+	if queue.pool != nil {
+		// Keep the nodes being walked from being recycled.
+		queue.active.Add(1)
+		defer queue.quiesce()
+	}
+	queue.readers.Add(1)
+	defer queue.readers.Add(-1)
+	rh := (*qNode[T])(atomic.LoadPointer(&queue.head))
+	for next := atomic.LoadPointer(&rh.next); next != nil; next = atomic.LoadPointer(&rh.next) {
+		rh = (*qNode[T])(next)
+		if !fn(rh.val) {
+			return
+		}
+	}
+}
+
+// enqueue links val in at the back of the queue without touching the length counter.
+func (queue *LockFreeQueue[T]) enqueue(val T) {
+	if queue.pool != nil {
+		queue.active.Add(1)
+		defer queue.quiesce()
+	}
+	node := queue.newNode(val)
+	queue.link(node, node)
+}
+
+// enqueueAll links vals in at the back of the queue as one chain, without touching the length
+// counter. vals must not be empty.
+func (queue *LockFreeQueue[T]) enqueueAll(vals []T) {
+	if queue.pool != nil {
+		queue.active.Add(1)
+		defer queue.quiesce()
+	}
+	first := queue.newNode(vals[0])
+	last := first
+	for _, val := range vals[1:] {
+		node := queue.newNode(val)
+		last.next = unsafe.Pointer(node)
+		last = node
+	}
+	queue.link(first, last)
+}
+
+// link appends the chain of nodes from first to last, which is not yet visible to any other
+// goroutine, with a single CAS on the tail node.
+func (queue *LockFreeQueue[T]) link(first, last *qNode[T]) {
+	for failures := 1; ; failures++ {
 		rt := (*qNode[T])(atomic.LoadPointer(&queue.tail))
 		//t := atomic.LoadPointer(&queue.tail)
 		//rt := (*qNode[T])(t)
-		if atomic.CompareAndSwapPointer(&rt.next, nil, node) {
-			atomic.StorePointer(&queue.tail, node)
+		if atomic.CompareAndSwapPointer(&rt.next, nil, unsafe.Pointer(first)) {
+			atomic.StorePointer(&queue.tail, unsafe.Pointer(last))
 			// If dead loop occurs, use CompareAndSwapPointer instead of StorePointer
 			// atomic.CompareAndSwapPointer(&queue.tail, t, node)
+			queue.notify()
 			return
 		} else {
+			queue.backoff.wait(failures)
 			continue
 		}
 	}
@@ -91,4 +406,18 @@ func (queue *LockFreeQueue[T]) Push(val T) {
 type qNode[T any] struct {
 	val  T
 	next unsafe.Pointer
+	// free links retired nodes awaiting recycling. It is kept apart from next,
+	// which stale readers may still inspect.
+	free unsafe.Pointer
+}
+
+// notify performs a non-blocking send on the ready channel, if any.
+func (queue *LockFreeQueue[T]) notify() {
+	if queue.ready == nil {
+		return
+	}
+	select {
+	case queue.ready <- struct{}{}:
+	default:
+	}
 }
@@ -4,7 +4,9 @@ import (
 	"runtime"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 const (
@@ -55,6 +57,9 @@ func TestQueue(t *testing.T) {
 	for v, ok := lfq.Pop(); ok; v, ok = lfq.Pop() {
 		resultBuf = append(resultBuf, v)
 	}
+	if lfq.Len() != 0 {
+		t.Error("Invalid length:", lfq.Len())
+	}
 	sort.Ints(resultBuf)
 	for i := 0; i != kPushingNum; i++ {
 		for j := 0; j != kGoRoutineNum; j++ {
@@ -65,6 +70,395 @@ func TestQueue(t *testing.T) {
 	}
 }
 
+func TestNotifyQueue(t *testing.T) {
+	q := NewNotifyQueue[int]()
+	drained := make(chan []int)
+	go func() {
+		<-q.Ready()
+		var got []int
+		for v, ok := q.Pop(); ok; v, ok = q.Pop() {
+			got = append(got, v)
+		}
+		drained <- got
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	q.Push(1)
+	select {
+	case got := <-drained:
+		if len(got) != 1 || got[0] != 1 {
+			t.Error("Invalid result:", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("worker was not woken by Push")
+	}
+
+	// Several pushes coalesce into a single pending signal.
+	q.Push(2)
+	q.Push(3)
+	<-q.Ready()
+	select {
+	case <-q.Ready():
+		t.Error("notifications were not coalesced")
+	default:
+	}
+
+	if NewQueue[int]().Ready() != nil {
+		t.Error("plain queue should not have a ready channel")
+	}
+}
+
+func TestQueue_ApproxLen(t *testing.T) {
+	q := NewQueue[int]()
+	if q.ApproxLen() != 0 {
+		t.Error("Invalid length:", q.ApproxLen())
+	}
+	for i := 0; i != 100; i++ {
+		q.Push(i)
+	}
+	for i := 0; i != 30; i++ {
+		q.Pop()
+	}
+	if q.ApproxLen() != 70 {
+		t.Error("Invalid length:", q.ApproxLen())
+	}
+}
+
+func TestNewQueueFromSlice(t *testing.T) {
+	items := []int{5, 3, 8, 1}
+	q := NewQueueFromSlice(items)
+	if q.Len() != int64(len(items)) {
+		t.Error("Invalid length:", q.Len())
+	}
+	q.Push(9)
+	for _, want := range append(items, 9) {
+		if v, ok := q.Pop(); !ok || v != want {
+			t.Error("Invalid result:", want, v, ok)
+		}
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Should be empty!")
+	}
+	if _, ok := NewQueueFromSlice[int](nil).Pop(); ok {
+		t.Error("Should be empty!")
+	}
+}
+
+func TestQueue_ForEach(t *testing.T) {
+	q := NewQueue[int]()
+	for i := 1; i <= 10; i++ {
+		q.Push(i)
+	}
+	q.Pop()
+
+	sum := 0
+	q.ForEach(func(v int) bool {
+		sum += v
+		return true
+	})
+	if sum != 54 {
+		t.Error("Invalid sum:", sum)
+	}
+
+	var seen []int
+	q.ForEach(func(v int) bool {
+		seen = append(seen, v)
+		return v < 4
+	})
+	if len(seen) != 3 || seen[0] != 2 || seen[2] != 4 {
+		t.Error("Invalid result:", seen)
+	}
+	if q.Len() != 9 {
+		t.Error("Invalid length:", q.Len())
+	}
+}
+
+func TestQueue_Close(t *testing.T) {
+	const n = 10000
+	q := NewQueue[int]()
+
+	var popped atomic.Int64
+	var consumers sync.WaitGroup
+	consumers.Add(kGoRoutineNum)
+	for i := 0; i != kGoRoutineNum; i++ {
+		go func() {
+			defer consumers.Done()
+			for {
+				_, ok, done := q.PopOrDone()
+				if done {
+					return
+				}
+				if ok {
+					popped.Add(1)
+				}
+			}
+		}()
+	}
+
+	var producers sync.WaitGroup
+	producers.Add(kGoRoutineNum)
+	for i := 0; i != kGoRoutineNum; i++ {
+		go func() {
+			defer producers.Done()
+			for j := 0; j != n; j++ {
+				q.Push(j)
+			}
+		}()
+	}
+	producers.Wait()
+	q.Close()
+	consumers.Wait()
+
+	if popped.Load() != n*kGoRoutineNum {
+		t.Error("Invalid result:", popped.Load())
+	}
+	if _, ok, done := q.PopOrDone(); ok || !done {
+		t.Error("closed queue should report done")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Push after Close should panic")
+		}
+		if q.Len() != 0 {
+			t.Error("Invalid length:", q.Len())
+		}
+	}()
+	q.Push(0)
+}
+
+func TestPopReleasesValue(t *testing.T) {
+	queue := NewQueue[*[1 << 20]byte]()
+	var collected atomic.Bool
+	func() {
+		big := new([1 << 20]byte)
+		runtime.SetFinalizer(big, func(*[1 << 20]byte) { collected.Store(true) })
+		queue.Push(big)
+	}()
+	if v, ok := queue.Pop(); !ok || v == nil {
+		t.Fatal("Invalid result:", ok)
+	}
+	// The popped node stays reachable as the dummy head until the next Pop, so its value must
+	// have been cleared for the finalizer to run.
+	queue.Push(new([1 << 20]byte))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !collected.Load() && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	if !collected.Load() {
+		t.Error("Popped value was not collected")
+	}
+	if queue.Len() != 1 {
+		t.Error("Invalid length:", queue.Len())
+	}
+}
+
+func TestCompareAndPop(t *testing.T) {
+	queue := NewQueue[int]()
+	if _, ok := CompareAndPop(queue, 0); ok {
+		t.Error("CompareAndPop on empty queue should fail")
+	}
+	queue.Push(1)
+	if _, ok := CompareAndPop(queue, 0); ok || queue.Len() != 1 {
+		t.Error("CompareAndPop of a different value should fail:", queue.Len())
+	}
+	if v, ok := CompareAndPop(queue, 1); !ok || v != 1 || queue.Len() != 0 {
+		t.Error("Invalid result:", v, ok, queue.Len())
+	}
+
+	// Goroutine i owns the values equal to i modulo kGoRoutineNum and spins on CompareAndPop until
+	// each of them reaches the front, so every pop must be made by its owner, in order.
+	const n = 10000
+	for i := 0; i != n; i++ {
+		queue.Push(i)
+	}
+	var wg sync.WaitGroup
+	popped := make([][]int, kGoRoutineNum)
+	wg.Add(kGoRoutineNum)
+	for i := 0; i != kGoRoutineNum; i++ {
+		go func(i int) {
+			defer wg.Done()
+			for expected := i; expected < n; expected += kGoRoutineNum {
+				for {
+					if v, ok := CompareAndPop(queue, expected); ok {
+						popped[i] = append(popped[i], v)
+						break
+					}
+					runtime.Gosched()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i := range popped {
+		if len(popped[i]) != n/kGoRoutineNum {
+			t.Error("Invalid length:", i, len(popped[i]))
+		}
+		for j, v := range popped[i] {
+			if v != i+j*kGoRoutineNum {
+				t.Error("Invalid result:", i, j, v)
+			}
+		}
+	}
+	if queue.Len() != 0 {
+		t.Error("Invalid length:", queue.Len())
+	}
+}
+
+func TestTransferTo(t *testing.T) {
+	src, dst := NewQueueFromSlice([]int{1, 2, 3, 4, 5}), NewQueue[int]()
+	if n := NewQueue[int]().TransferTo(dst, 3); n != 0 || dst.Len() != 0 {
+		t.Error("Transfer from empty queue:", n, dst.Len())
+	}
+	if n := src.TransferTo(dst, 0); n != 0 || src.Len() != 5 {
+		t.Error("Transfer of nothing:", n, src.Len())
+	}
+	if n := src.TransferTo(dst, 3); n != 3 || src.Len() != 2 || dst.Len() != 3 {
+		t.Error("Invalid length:", n, src.Len(), dst.Len())
+	}
+	if n := src.TransferTo(dst, 10); n != 2 || src.Len() != 0 || dst.Len() != 5 {
+		t.Error("Invalid length:", n, src.Len(), dst.Len())
+	}
+	for i := 1; i <= 5; i++ {
+		if v, ok := dst.Pop(); !ok || v != i {
+			t.Error("Invalid result:", i, v, ok)
+		}
+	}
+	src.Push(6)
+	if v, ok := src.Pop(); !ok || v != 6 {
+		t.Error("Invalid result:", v, ok)
+	}
+
+	dst.Close()
+	src.Push(7)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Transfer to closed queue should panic")
+			}
+		}()
+		src.TransferTo(dst, 1)
+	}()
+	if src.Len() != 1 || dst.Len() != 0 {
+		t.Error("Invalid length:", src.Len(), dst.Len())
+	}
+}
+
+func TestTransferToConcurrent(t *testing.T) {
+	const n = 100000
+	a, b := NewQueue[int](), NewPooledQueue[int]()
+	for i := 0; i != n; i++ {
+		a.Push(i)
+	}
+
+	// Shuffle elements back and forth while other goroutines pop and push them again.
+	var wg sync.WaitGroup
+	wg.Add(3 * kGoRoutineNum)
+	for i := 0; i != kGoRoutineNum; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j != 1000; j++ {
+				a.TransferTo(b, 37)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j != 1000; j++ {
+				b.TransferTo(a, 53)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j != 1000; j++ {
+				if v, ok := b.Pop(); ok {
+					a.Push(v)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if a.Len()+b.Len() != n {
+		t.Error("Invalid length:", a.Len(), b.Len())
+	}
+	var resultBuf []int
+	for _, q := range []*LockFreeQueue[int]{a, b} {
+		for v, ok := q.Pop(); ok; v, ok = q.Pop() {
+			resultBuf = append(resultBuf, v)
+		}
+	}
+	sort.Ints(resultBuf)
+	if len(resultBuf) != n {
+		t.Fatal("Invalid length:", len(resultBuf))
+	}
+	for i := range resultBuf {
+		if resultBuf[i] != i {
+			t.Fatal("Invalid result:", i, resultBuf[i])
+		}
+	}
+}
+
+func TestPopAll(t *testing.T) {
+	q := NewQueue[int]()
+	if vals := q.PopAll(); vals == nil || len(vals) != 0 {
+		t.Error("Invalid result:", vals)
+	}
+	for i := 0; i < kPushingNum; i++ {
+		q.Push(i)
+	}
+	vals := q.PopAll()
+	if len(vals) != kPushingNum {
+		t.Error("Invalid length:", len(vals))
+	}
+	for i, v := range vals {
+		if v != i {
+			t.Error("Invalid result:", i, v)
+			break
+		}
+	}
+	if q.Len() != 0 || q.ApproxLen() != 0 {
+		t.Error("Invalid length:", q.Len(), q.ApproxLen())
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Queue should be empty")
+	}
+	q.Push(1)
+	if v, ok := q.Pop(); !ok || v != 1 {
+		t.Error("Invalid result:", v, ok)
+	}
+
+	// Drain while producers push: every element is captured by exactly one PopAll.
+	var wg sync.WaitGroup
+	for i := 0; i < kGoRoutineNum; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < kPushingNum; j++ {
+				q.Push(j)
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	total := 0
+	for draining := true; draining; {
+		select {
+		case <-done:
+			draining = false
+		default:
+		}
+		total += len(q.PopAll())
+	}
+	if total != kGoRoutineNum*kPushingNum || q.Len() != 0 {
+		t.Error("Invalid length:", total, q.Len())
+	}
+}
+
 func push() {
 	for i := 0; i != kPushingNum; i++ {
 		lfq.Push(i)
@@ -0,0 +1,64 @@
+// Package lock_free_stack offers a goroutine-safe LockFreeStack implementation based on the Treiber algorithm.
+package lock_free_stack
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// LockFreeStack is a goroutine-safe LIFO stack implementation.
+//
+// Push and Pop retry a CompareAndSwapPointer on the head pointer until it succeeds. The classic
+// weakness of the Treiber stack is ABA: a Pop reads head A and its successor B, other goroutines
+// pop A, pop B and push A's memory back, and the stale CAS then succeeds and resurrects B. Here
+// every Push allocates a fresh node and popped nodes are never reused, so the garbage collector
+// keeps A alive for as long as any goroutine holds it and no other node can take its address.
+// Element values, pointer types included, are never compared: pushing the same pointer twice
+// creates two distinct nodes, so it cannot trigger ABA either.
+type LockFreeStack[T any] struct {
+	head unsafe.Pointer
+}
+
+// NewStack returns a new, ready-to-use LockFreeStack. The zero value is ready to use as well.
+//
+// Example:
+//
+//	lfs := lock_free_stack.NewStack[int]()
+//	lfs.Push(100)
+//	v, ok := lfs.Pop()
+func NewStack[T any]() *LockFreeStack[T] {
+	return &LockFreeStack[T]{}
+}
+
+// Push inserts an element on top of the stack.
+func (stack *LockFreeStack[T]) Push(val T) {
+	node := &sNode[T]{val: val}
+	for {
+		h := atomic.LoadPointer(&stack.head)
+		node.next = h
+		if atomic.CompareAndSwapPointer(&stack.head, h, unsafe.Pointer(node)) {
+			return
+		}
+	}
+}
+
+// Pop returns (and removes) the element on top of the stack and true if the stack is not empty,
+// otherwise it returns a default value and false.
+func (stack *LockFreeStack[T]) Pop() (T, bool) {
+	for {
+		h := atomic.LoadPointer(&stack.head)
+		if h == nil {
+			var v T
+			return v, false
+		}
+		rh := (*sNode[T])(h)
+		if atomic.CompareAndSwapPointer(&stack.head, h, rh.next) {
+			return rh.val, true
+		}
+	}
+}
+
+type sNode[T any] struct {
+	val  T
+	next unsafe.Pointer
+}
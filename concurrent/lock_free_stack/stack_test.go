@@ -0,0 +1,108 @@
+package lock_free_stack
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+)
+
+const (
+	kGoRoutineNum = 10
+	kPushingNum   = 100000
+	kBufSz        = kGoRoutineNum * kPushingNum
+)
+
+var out *testing.T
+var wg sync.WaitGroup
+var lfs = NewStack[int]()
+var popBuf [kGoRoutineNum][]int
+
+func TestStack(t *testing.T) {
+	runtime.GOMAXPROCS(runtime.NumCPU())
+	out = t
+	// init popBuf
+	for i := 0; i != kGoRoutineNum; i++ {
+		popBuf[i] = make([]int, 0, kBufSz)
+	}
+
+	// Push() simultaneously
+	wg.Add(kGoRoutineNum)
+	for i := 0; i != kGoRoutineNum; i++ {
+		go push()
+	}
+	wg.Wait()
+	// Pop() simultaneously
+	wg.Add(kGoRoutineNum)
+	for i := 0; i != kGoRoutineNum; i++ {
+		go popOnly()
+	}
+	wg.Wait()
+
+	// Push() and Pop() simultaneously
+	wg.Add(kGoRoutineNum * 2)
+	for i := 0; i != kGoRoutineNum; i++ {
+		go push()
+		go popWhilePushing(i)
+	}
+	wg.Wait()
+	// Verification
+	resultBuf := popBuf[0]
+	for i := 1; i != kGoRoutineNum; i++ {
+		resultBuf = append(resultBuf, popBuf[i]...)
+	}
+	// in case there are some elements left in the stack
+	for v, ok := lfs.Pop(); ok; v, ok = lfs.Pop() {
+		resultBuf = append(resultBuf, v)
+	}
+	sort.Ints(resultBuf)
+	for i := 0; i != kPushingNum; i++ {
+		for j := 0; j != kGoRoutineNum; j++ {
+			if resultBuf[(i*kGoRoutineNum)+j] != i {
+				t.Error("Invalid result:", i, j, resultBuf[(i*kGoRoutineNum)+j])
+			}
+		}
+	}
+}
+
+func TestStack_LIFO(t *testing.T) {
+	var s LockFreeStack[int]
+	for i := 0; i != 10; i++ {
+		s.Push(i)
+	}
+	for i := 9; i >= 0; i-- {
+		if v, ok := s.Pop(); !ok || v != i {
+			t.Error("Invalid result:", i, v, ok)
+		}
+	}
+	if _, ok := s.Pop(); ok {
+		t.Error("Should be empty!")
+	}
+}
+
+func push() {
+	for i := 0; i != kPushingNum; i++ {
+		lfs.Push(i)
+	}
+	wg.Done()
+}
+
+func popOnly() {
+	for i := 0; i != kPushingNum; i++ {
+		_, ok := lfs.Pop()
+		if !ok {
+			out.Error("Should never be nil!")
+		}
+	}
+	wg.Done()
+}
+
+func popWhilePushing(n int) {
+	for i := 0; i != kPushingNum*2; i++ {
+		v, ok := lfs.Pop()
+		if ok {
+			popBuf[n] = append(popBuf[n], v)
+		}
+	}
+	wg.Done()
+}
@@ -0,0 +1,132 @@
+// Package monotonic provides a monotonic queue, which tracks the minimum or
+// maximum of a sliding window in O(1).
+package monotonic
+
+import (
+	"sync"
+
+	"github.com/eyotang/container/concurrent/deque"
+)
+
+// Mode selects the extreme a MonotonicQueue tracks.
+type Mode int
+
+const (
+	// TrackMin makes Min report the smallest element of the window.
+	TrackMin Mode = iota
+	// TrackMax makes Max report the largest element of the window.
+	TrackMax
+)
+
+// candidate is an element which may still become the extreme of the window,
+// along with its sequence number.
+type candidate[T any] struct {
+	val T
+	seq uint64
+}
+
+// MonotonicQueue is a goroutine-safe FIFO window which reports its minimum or
+// maximum in O(1). Besides the window itself it keeps, in a second deque, the
+// elements which may still become the extreme once older ones leave; every
+// element enters and leaves that deque at most once, so Push and PopFront are
+// O(1) amortized.
+type MonotonicQueue[T any] struct {
+	lock   sync.Mutex
+	window *deque.Deque[T]
+	// candidates is ordered from the current extreme at the front to the
+	// newest element at the back, monotonically according to mode.
+	candidates *deque.Deque[candidate[T]]
+	less       func(a, b T) bool
+	mode       Mode
+	// pushed and popped count the elements which entered and left the window.
+	pushed, popped uint64
+}
+
+// NewMonotonicQueue constructs and returns a new, empty MonotonicQueue
+// ordering elements by less and tracking the extreme selected by mode.
+//
+// Example:
+//
+//	q := monotonic.NewMonotonicQueue(func(a, b int) bool { return a < b }, monotonic.TrackMax)
+//	q.Push(3)
+//	q.Push(1)
+//	max, ok := q.Max()
+func NewMonotonicQueue[T any](less func(a, b T) bool, mode Mode) *MonotonicQueue[T] {
+	return &MonotonicQueue[T]{
+		window:     deque.NewDeque[T](),
+		candidates: deque.NewDeque[candidate[T]](),
+		less:       less,
+		mode:       mode,
+	}
+}
+
+// dominates reports whether a newer element x makes an older candidate c
+// useless, as c leaves the window first and is not more extreme than x.
+func (q *MonotonicQueue[T]) dominates(x, c T) bool {
+	if q.mode == TrackMax {
+		return q.less(c, x)
+	}
+	return q.less(x, c)
+}
+
+// Push puts an element on the back of the window.
+func (q *MonotonicQueue[T]) Push(elem T) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.window.PushBack(elem)
+	for {
+		back, ok := q.candidates.Back()
+		if !ok || !q.dominates(elem, back.val) {
+			break
+		}
+		q.candidates.PopBack()
+	}
+	q.candidates.PushBack(candidate[T]{val: elem, seq: q.pushed})
+	q.pushed++
+}
+
+// PopFront removes and returns the oldest element of the window and true, or
+// a default value and false if the window is empty.
+func (q *MonotonicQueue[T]) PopFront() (T, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	v, ok := q.window.PopFront()
+	if !ok {
+		return v, false
+	}
+	if front, _ := q.candidates.Front(); front.seq == q.popped {
+		q.candidates.PopFront()
+	}
+	q.popped++
+	return v, true
+}
+
+// Min returns the smallest element of the window and true, or a default value
+// and false if the window is empty. It panics unless the queue tracks TrackMin.
+func (q *MonotonicQueue[T]) Min() (T, bool) {
+	if q.mode != TrackMin {
+		panic("monotonic: Min() called on a queue tracking the maximum")
+	}
+	return q.extreme()
+}
+
+// Max returns the largest element of the window and true, or a default value
+// and false if the window is empty. It panics unless the queue tracks TrackMax.
+func (q *MonotonicQueue[T]) Max() (T, bool) {
+	if q.mode != TrackMax {
+		panic("monotonic: Max() called on a queue tracking the minimum")
+	}
+	return q.extreme()
+}
+
+func (q *MonotonicQueue[T]) extreme() (T, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	c, ok := q.candidates.Front()
+	return c.val, ok
+}
+
+// Len returns the number of elements in the window.
+func (q *MonotonicQueue[T]) Len() int {
+	return q.window.Len()
+}
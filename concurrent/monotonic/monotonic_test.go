@@ -0,0 +1,89 @@
+package monotonic
+
+import (
+	"math/rand"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// slidingWindow returns the extreme of every window of k consecutive nums,
+// as reported by a MonotonicQueue in the given mode.
+func slidingWindow(nums []int, k int, mode Mode) []int {
+	q := NewMonotonicQueue(func(a, b int) bool { return a < b }, mode)
+	var out []int
+	for i, x := range nums {
+		q.Push(x)
+		if i >= k {
+			q.PopFront()
+		}
+		if i >= k-1 {
+			var v int
+			if mode == TrackMax {
+				v, _ = q.Max()
+			} else {
+				v, _ = q.Min()
+			}
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func TestMonotonicQueue(t *testing.T) {
+	Convey("test MonotonicQueue", t, func() {
+		Convey("test MonotonicQueue sliding window maximum", func() {
+			nums := []int{1, 3, -1, -3, 5, 3, 6, 7}
+			So(slidingWindow(nums, 3, TrackMax), ShouldResemble, []int{3, 3, 5, 5, 6, 7})
+			So(slidingWindow(nums, 3, TrackMin), ShouldResemble, []int{-1, -3, -3, -3, 3, 3})
+			So(slidingWindow(nums, 1, TrackMax), ShouldResemble, nums)
+			So(slidingWindow([]int{4, 2, 12, 11, -5}, 2, TrackMax), ShouldResemble, []int{4, 12, 12, 11})
+		})
+
+		Convey("test MonotonicQueue duplicates", func() {
+			So(slidingWindow([]int{2, 2, 1, 2, 1, 1}, 2, TrackMax), ShouldResemble, []int{2, 2, 2, 2, 1})
+			So(slidingWindow([]int{1, 1, 2, 1, 2, 2}, 2, TrackMin), ShouldResemble, []int{1, 1, 1, 1, 2})
+		})
+
+		Convey("test MonotonicQueue against brute force", func() {
+			r := rand.New(rand.NewSource(1))
+			nums := make([]int, 500)
+			for i := range nums {
+				nums[i] = r.Intn(50)
+			}
+			const k = 7
+			got := slidingWindow(nums, k, TrackMax)
+			for i := range got {
+				want := nums[i]
+				for _, x := range nums[i : i+k] {
+					want = max(want, x)
+				}
+				So(got[i], ShouldEqual, want)
+			}
+		})
+
+		Convey("test MonotonicQueue PopFront", func() {
+			q := NewMonotonicQueue(func(a, b int) bool { return a < b }, TrackMin)
+			_, ok := q.PopFront()
+			So(ok, ShouldBeFalse)
+			_, ok = q.Min()
+			So(ok, ShouldBeFalse)
+			for _, x := range []int{5, 1, 3} {
+				q.Push(x)
+			}
+			So(q.Len(), ShouldEqual, 3)
+			v, _ := q.PopFront()
+			So(v, ShouldEqual, 5)
+			v, _ = q.Min()
+			So(v, ShouldEqual, 1)
+			v, _ = q.PopFront()
+			So(v, ShouldEqual, 1)
+			v, _ = q.Min()
+			So(v, ShouldEqual, 3)
+			q.PopFront()
+			_, ok = q.Min()
+			So(ok, ShouldBeFalse)
+			So(func() { q.Max() }, ShouldPanic)
+		})
+	})
+}
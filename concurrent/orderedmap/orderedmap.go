@@ -0,0 +1,101 @@
+// Package orderedmap provides a map which remembers the order of its keys.
+package orderedmap
+
+import (
+	"iter"
+	"sync"
+
+	"github.com/eyotang/container/concurrent/queue"
+)
+
+// SetPolicy decides where Set places a key which is already present.
+type SetPolicy int
+
+const (
+	// KeepPosition leaves an updated key where it was first set.
+	KeepPosition SetPolicy = iota
+	// MoveToBack moves an updated key behind every other key, as for a
+	// recently-used order.
+	MoveToBack
+)
+
+// OrderedMap is a goroutine-safe map whose keys are kept in a ring-buffer
+// queue in insertion order. Lookups are O(1); Delete, and Set under the
+// MoveToBack policy, are O(n) in the number of keys.
+type OrderedMap[K comparable, V any] struct {
+	lock   sync.RWMutex
+	values map[K]V
+	order  *queue.Queue[K]
+	policy SetPolicy
+}
+
+// NewOrderedMap constructs and returns a new, empty OrderedMap using policy.
+func NewOrderedMap[K comparable, V any](policy SetPolicy) *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{
+		values: make(map[K]V),
+		order:  queue.NewQueue[K](),
+		policy: policy,
+	}
+}
+
+// Set maps key to val. A new key is placed after every other key; an existing
+// one is placed according to the policy.
+func (m *OrderedMap[K, V]) Set(key K, val V) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.values[key]; !ok {
+		m.order.Push(key)
+	} else if m.policy == MoveToBack {
+		m.order.MoveToBack(m.order.Index(key))
+	}
+	m.values[key] = val
+}
+
+// Get returns the value mapped to key and true, or a default value and false
+// if key is not present.
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	val, ok := m.values[key]
+	return val, ok
+}
+
+// Delete removes key and returns true, or returns false if key is not present.
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.values[key]; !ok {
+		return false
+	}
+	delete(m.values, key)
+	m.order.MoveToFront(m.order.Index(key))
+	m.order.Pop()
+	return true
+}
+
+// Len returns the number of keys.
+func (m *OrderedMap[K, V]) Len() int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return len(m.values)
+}
+
+// Range returns an iterator over the keys and their values in order. It
+// iterates over a snapshot taken when iteration starts, so the map may be
+// modified while iterating.
+func (m *OrderedMap[K, V]) Range() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.lock.RLock()
+		keys := m.order.Items()
+		vals := make([]V, len(keys))
+		for i, key := range keys {
+			vals[i] = m.values[key]
+		}
+		m.lock.RUnlock()
+		for i, key := range keys {
+			if !yield(key, vals[i]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package orderedmap
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOrderedMap(t *testing.T) {
+	Convey("test OrderedMap", t, func() {
+		Convey("test OrderedMap keep position", func() {
+			m := NewOrderedMap[string, int](KeepPosition)
+			m.Set("b", 1)
+			m.Set("a", 2)
+			m.Set("c", 3)
+			m.Set("b", 4)
+			So(collect(m), ShouldResemble, []string{"b=4", "a=2", "c=3"})
+			v, ok := m.Get("b")
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, 4)
+			_, ok = m.Get("d")
+			So(ok, ShouldBeFalse)
+			So(m.Len(), ShouldEqual, 3)
+		})
+
+		Convey("test OrderedMap move to back", func() {
+			m := NewOrderedMap[string, int](MoveToBack)
+			m.Set("b", 1)
+			m.Set("a", 2)
+			m.Set("c", 3)
+			m.Set("b", 4)
+			So(collect(m), ShouldResemble, []string{"a=2", "c=3", "b=4"})
+			m.Set("b", 5)
+			So(collect(m), ShouldResemble, []string{"a=2", "c=3", "b=5"})
+		})
+
+		Convey("test OrderedMap delete", func() {
+			m := NewOrderedMap[string, int](KeepPosition)
+			m.Set("b", 1)
+			m.Set("a", 2)
+			m.Set("c", 3)
+			So(m.Delete("d"), ShouldBeFalse)
+			So(m.Delete("a"), ShouldBeTrue)
+			So(m.Delete("a"), ShouldBeFalse)
+			So(m.Len(), ShouldEqual, 2)
+			So(collect(m), ShouldResemble, []string{"b=1", "c=3"})
+
+			// A deleted key comes back at the end.
+			m.Set("a", 6)
+			So(collect(m), ShouldResemble, []string{"b=1", "c=3", "a=6"})
+
+			var first []string
+			for key := range m.Range() {
+				first = append(first, key)
+				break
+			}
+			So(first, ShouldResemble, []string{"b"})
+		})
+	})
+}
+
+// collect renders the entries of m as key=value in order.
+func collect(m *OrderedMap[string, int]) []string {
+	var items []string
+	for key, val := range m.Range() {
+		items = append(items, fmt.Sprintf("%s=%d", key, val))
+	}
+	return items
+}
@@ -0,0 +1,76 @@
+package queue
+
+import (
+	"context"
+	"time"
+)
+
+// Batcher groups the elements of a Queue into batches for consumers which
+// write downstream in bulk. A batch is emitted once it holds maxSize elements,
+// or once maxDelay has passed since its first element was popped, whichever
+// comes first.
+type Batcher[T comparable] struct {
+	q        *Queue[T]
+	maxSize  int
+	maxDelay time.Duration
+}
+
+// NewBatcher constructs and returns a new Batcher over q. This call panics if
+// maxSize or maxDelay is not positive.
+func NewBatcher[T comparable](q *Queue[T], maxSize int, maxDelay time.Duration) *Batcher[T] {
+	if maxSize <= 0 || maxDelay <= 0 {
+		panic("queue: NewBatcher() called with non-positive limit")
+	}
+	return &Batcher[T]{q: q, maxSize: maxSize, maxDelay: maxDelay}
+}
+
+// Batches starts a goroutine popping elements from the queue with PopWait and
+// sending them, grouped into batches, on the returned channel. Once ctx is
+// done the goroutine puts back at the head, in order, the elements of any
+// batch not yet delivered, closes the channel and exits. Once the queue is
+// closed and drained, the last batch is delivered and the channel closed.
+// Like Channel, it competes with any other consumer of the queue.
+func (b *Batcher[T]) Batches(ctx context.Context) <-chan []T {
+	ch := make(chan []T)
+	go func() {
+		defer close(ch)
+		for {
+			first, err := b.q.PopWait(ctx)
+			if err != nil {
+				return
+			}
+			batch := make([]T, 1, b.maxSize)
+			batch[0] = first
+			batchCtx, cancel := context.WithTimeout(ctx, b.maxDelay)
+			for len(batch) < b.maxSize {
+				v, err := b.q.PopWait(batchCtx)
+				if err != nil {
+					break
+				}
+				batch = append(batch, v)
+			}
+			cancel()
+			if ctx.Err() != nil {
+				b.putBack(batch)
+				return
+			}
+			select {
+			case ch <- batch:
+			case <-ctx.Done():
+				b.putBack(batch)
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// putBack returns an undelivered batch to the head of the queue.
+func (b *Batcher[T]) putBack(batch []T) {
+	b.q.lock.Lock()
+	for i := len(batch) - 1; i >= 0; i-- {
+		b.q.pushFront(batch[i])
+	}
+	b.q.lock.Unlock()
+	b.q.fireWatermarks()
+}
@@ -0,0 +1,75 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBatcher(t *testing.T) {
+	Convey("test Batcher", t, func() {
+		q := NewQueue[int]()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		Convey("test Batcher size trigger", func() {
+			for i := 0; i < 10; i++ {
+				q.Push(i)
+			}
+			batches := NewBatcher(q, 4, time.Hour).Batches(ctx)
+			So(<-batches, ShouldResemble, []int{0, 1, 2, 3})
+			So(<-batches, ShouldResemble, []int{4, 5, 6, 7})
+		})
+
+		Convey("test Batcher timeout trigger", func() {
+			batches := NewBatcher(q, 100, 20*time.Millisecond).Batches(ctx)
+			start := time.Now()
+			q.Push(1)
+			q.Push(2)
+			q.Push(3)
+			So(<-batches, ShouldResemble, []int{1, 2, 3})
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 20*time.Millisecond)
+
+			go func() {
+				time.Sleep(5 * time.Millisecond)
+				q.Push(4)
+			}()
+			So(<-batches, ShouldResemble, []int{4})
+		})
+
+		Convey("test Batcher shutdown", func() {
+			batches := NewBatcher(q, 100, time.Hour).Batches(ctx)
+			q.Push(1)
+			q.Push(2)
+			time.Sleep(10 * time.Millisecond)
+			q.Push(3)
+			cancel()
+			_, ok := <-batches
+			So(ok, ShouldBeFalse)
+			// The undelivered batch is back in the queue, in order.
+			So(q.Items(), ShouldResemble, []int{1, 2, 3})
+		})
+
+		Convey("test Batcher closed queue", func() {
+			batches := NewBatcher(q, 100, time.Hour).Batches(ctx)
+			q.Push(1)
+			q.Push(2)
+			time.Sleep(10 * time.Millisecond)
+			q.Close()
+			So(<-batches, ShouldResemble, []int{1, 2})
+			select {
+			case _, ok := <-batches:
+				So(ok, ShouldBeFalse)
+			case <-time.After(time.Second):
+				So("Batches still open after Close", ShouldBeEmpty)
+			}
+		})
+
+		Convey("test NewBatcher panics", func() {
+			So(func() { NewBatcher(q, 0, time.Second) }, ShouldPanicWith, "queue: NewBatcher() called with non-positive limit")
+			So(func() { NewBatcher(q, 1, 0) }, ShouldPanicWith, "queue: NewBatcher() called with non-positive limit")
+		})
+	})
+}
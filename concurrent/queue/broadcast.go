@@ -0,0 +1,53 @@
+package queue
+
+import "sync"
+
+// Broadcaster delivers every pushed element to each of its subscribers, each
+// of which consumes from a Queue of its own. Subscriber queues are unbounded,
+// so a subscriber which stops popping keeps growing until it unsubscribes.
+type Broadcaster[T comparable] struct {
+	lock   sync.Mutex
+	subs   map[int]*Queue[T]
+	nextID int
+}
+
+// NewBroadcaster constructs and returns a new Broadcaster with no subscribers.
+func NewBroadcaster[T comparable]() *Broadcaster[T] {
+	return &Broadcaster[T]{subs: make(map[int]*Queue[T])}
+}
+
+// Subscribe registers a new subscriber and returns its id and the queue it
+// receives elements on. Only elements pushed after Subscribe are delivered.
+func (b *Broadcaster[T]) Subscribe() (id int, q *Queue[T]) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	id, q = b.nextID, NewQueue[T]()
+	b.nextID++
+	b.subs[id] = q
+	return id, q
+}
+
+// Unsubscribe stops delivery to the subscriber with the given id. Elements
+// already in its queue stay there.
+func (b *Broadcaster[T]) Unsubscribe(id int) {
+	b.lock.Lock()
+	delete(b.subs, id)
+	b.lock.Unlock()
+}
+
+// Push puts elem on the end of every subscriber's queue. Pushes are
+// serialized, so all subscribers see elements in the same order.
+func (b *Broadcaster[T]) Push(elem T) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, q := range b.subs {
+		q.Push(elem)
+	}
+}
+
+// Subscribers returns the number of registered subscribers.
+func (b *Broadcaster[T]) Subscribers() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return len(b.subs)
+}
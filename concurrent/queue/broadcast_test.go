@@ -0,0 +1,49 @@
+package queue
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBroadcaster(t *testing.T) {
+	Convey("test Broadcaster", t, func() {
+		b := NewBroadcaster[int]()
+		b.Push(-1)
+		id1, q1 := b.Subscribe()
+		id2, q2 := b.Subscribe()
+		So(id1, ShouldNotEqual, id2)
+		So(b.Subscribers(), ShouldEqual, 2)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 250; j++ {
+					b.Push(i*1000 + j)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		items := q1.Items()
+		So(len(items), ShouldEqual, 1000)
+		So(q2.Items(), ShouldResemble, items)
+		// Each pusher's elements keep their order.
+		last := map[int]int{0: -1, 1: -1, 2: -1, 3: -1}
+		for _, v := range items {
+			So(v%1000, ShouldBeGreaterThan, last[v/1000])
+			last[v/1000] = v % 1000
+		}
+
+		Convey("test Broadcaster Unsubscribe", func() {
+			b.Unsubscribe(id1)
+			b.Push(5)
+			So(q1.Size(), ShouldEqual, 1000)
+			So(q2.Get(-1), ShouldEqual, 5)
+			So(b.Subscribers(), ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,64 @@
+package queue
+
+// costEntry is an element of a CostQueue along with its cost.
+type costEntry[T comparable] struct {
+	elem T
+	cost int64
+}
+
+// CostQueue is a Queue bounded by the total cost of its elements rather than
+// their count, e.g. a byte budget.
+type CostQueue[T comparable] struct {
+	q       *Queue[costEntry[T]]
+	maxCost int64
+	cost    func(T) int64
+	total   int64
+}
+
+// NewCostQueue constructs and returns a new CostQueue whose elements, as
+// measured by cost, may add up to at most maxCost. cost is evaluated once per
+// element, when it is pushed.
+func NewCostQueue[T comparable](maxCost int64, cost func(T) int64) *CostQueue[T] {
+	return &CostQueue[T]{
+		q:       NewQueue[costEntry[T]](),
+		maxCost: maxCost,
+		cost:    cost,
+	}
+}
+
+// Push puts an element on the end of the queue and returns true, or returns
+// false if its cost would take the total past maxCost.
+func (c *CostQueue[T]) Push(elem T) bool {
+	cost := c.cost(elem)
+	c.q.lock.Lock()
+	defer c.q.lock.Unlock()
+	if c.total+cost > c.maxCost {
+		return false
+	}
+	c.total += cost
+	c.q.push(costEntry[T]{elem: elem, cost: cost})
+	return true
+}
+
+// Pop removes and returns the element from the front of the queue, freeing
+// its cost, and true. It returns a default value and false if the queue is empty.
+func (c *CostQueue[T]) Pop() (T, bool) {
+	c.q.lock.Lock()
+	defer c.q.lock.Unlock()
+	e, ok := c.q.pop()
+	c.total -= e.cost
+	return e.elem, ok
+}
+
+// Cost returns the total cost of the elements currently stored in the queue.
+func (c *CostQueue[T]) Cost() int64 {
+	c.q.lock.RLock()
+	total := c.total
+	c.q.lock.RUnlock()
+	return total
+}
+
+// Size returns the number of elements currently stored in the queue.
+func (c *CostQueue[T]) Size() int {
+	return c.q.Size()
+}
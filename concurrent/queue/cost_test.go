@@ -0,0 +1,45 @@
+package queue
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCostQueue(t *testing.T) {
+	Convey("test CostQueue", t, func() {
+		q := NewCostQueue[string](10, func(s string) int64 { return int64(len(s)) })
+
+		Convey("test CostQueue up to the limit", func() {
+			So(q.Push("abcd"), ShouldBeTrue)
+			So(q.Push("ef"), ShouldBeTrue)
+			So(q.Push("ghij"), ShouldBeTrue)
+			So(q.Cost(), ShouldEqual, 10)
+			So(q.Size(), ShouldEqual, 3)
+		})
+
+		Convey("test CostQueue past the limit", func() {
+			So(q.Push("abcdefgh"), ShouldBeTrue)
+			So(q.Push("ijk"), ShouldBeFalse)
+			So(q.Push("ij"), ShouldBeTrue)
+			So(q.Push(""), ShouldBeTrue)
+			So(q.Cost(), ShouldEqual, 10)
+		})
+
+		Convey("test CostQueue pop frees budget", func() {
+			So(q.Push("abcdefgh"), ShouldBeTrue)
+			So(q.Push("ijk"), ShouldBeFalse)
+			v, ok := q.Pop()
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, "abcdefgh")
+			So(q.Cost(), ShouldEqual, 0)
+			So(q.Push("ijk"), ShouldBeTrue)
+			So(q.Cost(), ShouldEqual, 3)
+
+			q.Pop()
+			_, ok = q.Pop()
+			So(ok, ShouldBeFalse)
+			So(q.Cost(), ShouldEqual, 0)
+		})
+	})
+}
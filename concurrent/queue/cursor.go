@@ -0,0 +1,77 @@
+package queue
+
+// CursorQueue is a Queue read through a cursor, so that elements can be
+// processed tentatively and rolled back before they are consumed. Advance
+// reads ahead without popping, Mark and Rewind save and restore the cursor,
+// and Commit pops everything the cursor has passed.
+type CursorQueue[T comparable] struct {
+	q *Queue[T]
+	// cursor and mark are offsets from the head, with mark <= cursor.
+	cursor, mark int
+}
+
+// NewCursorQueue constructs and returns a new, empty CursorQueue.
+func NewCursorQueue[T comparable]() *CursorQueue[T] {
+	return &CursorQueue[T]{q: NewQueue[T]()}
+}
+
+// Push puts an element on the end of the queue.
+func (c *CursorQueue[T]) Push(elem T) {
+	c.q.Push(elem)
+}
+
+// Advance returns the element under the cursor and true, moving the cursor
+// past it, or a default value and false if the cursor is at the end of the
+// queue. The element stays queued until Commit.
+func (c *CursorQueue[T]) Advance() (T, bool) {
+	c.q.lock.Lock()
+	defer c.q.lock.Unlock()
+	i, ok := c.q.physicalIndex(c.cursor)
+	if !ok {
+		var v T
+		return v, false
+	}
+	c.cursor++
+	return c.q.buf[i], true
+}
+
+// Mark records the current cursor position for Rewind.
+func (c *CursorQueue[T]) Mark() {
+	c.q.lock.Lock()
+	c.mark = c.cursor
+	c.q.lock.Unlock()
+}
+
+// Rewind moves the cursor back to the last Mark, or to the head of the queue
+// if there is none since the last Commit.
+func (c *CursorQueue[T]) Rewind() {
+	c.q.lock.Lock()
+	c.cursor = c.mark
+	c.q.lock.Unlock()
+}
+
+// Commit pops every element the cursor has passed and returns how many were
+// popped. The cursor and the mark are reset to the new head.
+func (c *CursorQueue[T]) Commit() int {
+	c.q.lock.Lock()
+	n := c.cursor
+	for i := 0; i < n; i++ {
+		c.q.pop()
+	}
+	c.cursor, c.mark = 0, 0
+	c.q.lock.Unlock()
+	return n
+}
+
+// Pending returns the number of elements the cursor has passed but which are
+// not committed yet.
+func (c *CursorQueue[T]) Pending() int {
+	c.q.lock.RLock()
+	defer c.q.lock.RUnlock()
+	return c.cursor
+}
+
+// Size returns the number of elements in the queue, including those pending.
+func (c *CursorQueue[T]) Size() int {
+	return c.q.Size()
+}
@@ -0,0 +1,56 @@
+package queue
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCursorQueue(t *testing.T) {
+	Convey("test CursorQueue", t, func() {
+		c := NewCursorQueue[int]()
+		_, ok := c.Advance()
+		So(ok, ShouldBeFalse)
+		for i := 0; i < 5; i++ {
+			c.Push(i)
+		}
+
+		advance := func(n int) []int {
+			var got []int
+			for ; n > 0; n-- {
+				v, ok := c.Advance()
+				So(ok, ShouldBeTrue)
+				got = append(got, v)
+			}
+			return got
+		}
+
+		So(advance(2), ShouldResemble, []int{0, 1})
+		c.Mark()
+		So(advance(2), ShouldResemble, []int{2, 3})
+		So(c.Pending(), ShouldEqual, 4)
+		So(c.Size(), ShouldEqual, 5)
+
+		c.Rewind()
+		So(c.Pending(), ShouldEqual, 2)
+		So(advance(3), ShouldResemble, []int{2, 3, 4})
+		_, ok = c.Advance()
+		So(ok, ShouldBeFalse)
+
+		c.Rewind()
+		So(c.Commit(), ShouldEqual, 2)
+		So(c.Size(), ShouldEqual, 3)
+		So(c.Pending(), ShouldEqual, 0)
+		So(c.q.Items(), ShouldResemble, []int{2, 3, 4})
+
+		Convey("test CursorQueue rewind without mark", func() {
+			So(advance(2), ShouldResemble, []int{2, 3})
+			c.Rewind()
+			c.Push(5)
+			So(advance(4), ShouldResemble, []int{2, 3, 4, 5})
+			So(c.Commit(), ShouldEqual, 4)
+			So(c.Size(), ShouldEqual, 0)
+			So(c.Commit(), ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,83 @@
+package queue
+
+// failedItem is a dead letter: a value which failed processing, with the last
+// error and the number of times it was retried before.
+type failedItem[T comparable] struct {
+	val     T
+	err     error
+	retries int
+}
+
+// DeadLetterQueue collects values which failed processing, so that they can
+// be inspected or fed back to the primary queue for another attempt. Retry
+// counts follow a value across Requeue: when a requeued value fails again,
+// its dead letter records one more retry. Values are matched by ==, so
+// distinct values should be distinguishable, e.g. by carrying an ID.
+//
+// The count of a requeued value is kept until it fails again, so a value
+// which then succeeds must be passed to Forget, or its count is kept forever.
+type DeadLetterQueue[T comparable] struct {
+	q *Queue[failedItem[T]]
+	// retries holds the counts of values requeued and not yet failed again
+	// or forgotten.
+	retries map[T]int
+}
+
+// NewDeadLetterQueue constructs and returns a new, empty DeadLetterQueue.
+func NewDeadLetterQueue[T comparable]() *DeadLetterQueue[T] {
+	return &DeadLetterQueue[T]{
+		q:       NewQueue[failedItem[T]](),
+		retries: make(map[T]int),
+	}
+}
+
+// Fail records that processing val failed with err.
+func (d *DeadLetterQueue[T]) Fail(val T, err error) {
+	d.q.lock.Lock()
+	defer d.q.lock.Unlock()
+	retries := d.retries[val]
+	delete(d.retries, val)
+	d.q.push(failedItem[T]{val: val, err: err, retries: retries})
+}
+
+// Pop removes the oldest dead letter and returns its value, error and retry
+// count, and true. It returns ok=false if the queue is empty.
+func (d *DeadLetterQueue[T]) Pop() (val T, err error, retries int, ok bool) {
+	item, ok := d.q.Pop()
+	return item.val, item.err, item.retries, ok
+}
+
+// Requeue moves every dead letter, oldest first, back to primary for another
+// attempt and returns how many were moved. Their retry counts are incremented
+// and kept until they fail again or are forgotten: the caller must call Forget
+// for each requeued value that is then processed successfully, otherwise its
+// count is never released and the DeadLetterQueue grows without bound.
+func (d *DeadLetterQueue[T]) Requeue(primary *Queue[T]) int {
+	d.q.lock.Lock()
+	items := make([]failedItem[T], 0, d.q.count)
+	for d.q.count > 0 {
+		item, _ := d.q.pop()
+		d.retries[item.val] = item.retries + 1
+		items = append(items, item)
+	}
+	d.q.lock.Unlock()
+	// Push outside the lock, as a bounded primary may block.
+	for _, item := range items {
+		primary.Push(item.val)
+	}
+	return len(items)
+}
+
+// Forget drops the retry count kept for a requeued val. It must be called
+// once val has been processed successfully, see Requeue; forgetting a value
+// with no count kept is a no-op.
+func (d *DeadLetterQueue[T]) Forget(val T) {
+	d.q.lock.Lock()
+	delete(d.retries, val)
+	d.q.lock.Unlock()
+}
+
+// Size returns the number of dead letters.
+func (d *DeadLetterQueue[T]) Size() int {
+	return d.q.Size()
+}
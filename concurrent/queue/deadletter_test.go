@@ -0,0 +1,83 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDeadLetterQueue(t *testing.T) {
+	Convey("test DeadLetterQueue", t, func() {
+		errA, errB := errors.New("a"), errors.New("b")
+		d := NewDeadLetterQueue[string]()
+		_, _, _, ok := d.Pop()
+		So(ok, ShouldBeFalse)
+
+		d.Fail("job1", errA)
+		d.Fail("job2", errB)
+		So(d.Size(), ShouldEqual, 2)
+
+		Convey("test DeadLetterQueue Pop metadata", func() {
+			val, err, retries, ok := d.Pop()
+			So(ok, ShouldBeTrue)
+			So(val, ShouldEqual, "job1")
+			So(err, ShouldEqual, errA)
+			So(retries, ShouldEqual, 0)
+			val, err, _, _ = d.Pop()
+			So(val, ShouldEqual, "job2")
+			So(err, ShouldEqual, errB)
+		})
+
+		Convey("test DeadLetterQueue Requeue", func() {
+			primary := NewQueue[string]()
+			primary.Push("job0")
+			So(d.Requeue(primary), ShouldEqual, 2)
+			So(d.Size(), ShouldEqual, 0)
+			So(primary.Items(), ShouldResemble, []string{"job0", "job1", "job2"})
+
+			// job1 fails again and carries its retry, job2 succeeds.
+			primary.Pop()
+			job, _ := primary.Pop()
+			d.Fail(job, errB)
+			job, _ = primary.Pop()
+			d.Forget(job)
+			So(d.retries, ShouldHaveLength, 0)
+
+			val, err, retries, _ := d.Pop()
+			So(val, ShouldEqual, "job1")
+			So(err, ShouldEqual, errB)
+			So(retries, ShouldEqual, 1)
+
+			// Popping a dead letter without requeueing it ends its retries.
+			d.Fail("job1", errA)
+			d.Requeue(primary)
+			job, _ = primary.Pop()
+			d.Fail(job, errA)
+			_, _, retries, _ = d.Pop()
+			So(retries, ShouldEqual, 1)
+		})
+
+		Convey("test DeadLetterQueue Forget releases requeued counts", func() {
+			primary := NewQueue[string]()
+			for i := 0; i < 3; i++ {
+				d.Requeue(primary)
+				So(d.retries, ShouldHaveLength, 2)
+				for primary.Size() > 0 {
+					job, _ := primary.Pop()
+					d.Fail(job, errA)
+				}
+			}
+			d.Requeue(primary)
+			// Every job succeeds this time: without Forget the counts stay.
+			So(d.retries, ShouldResemble, map[string]int{"job1": 4, "job2": 4})
+			for primary.Size() > 0 {
+				job, _ := primary.Pop()
+				d.Forget(job)
+			}
+			So(d.retries, ShouldBeEmpty)
+			d.Forget("job1")
+			So(d.retries, ShouldBeEmpty)
+		})
+	})
+}
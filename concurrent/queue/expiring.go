@@ -0,0 +1,88 @@
+package queue
+
+import "time"
+
+// deadlineEntry is an element of an ExpiringBoundedQueue along with the time
+// after which it is no longer wanted.
+type deadlineEntry[T comparable] struct {
+	elem     T
+	deadline time.Time
+}
+
+// ExpiringBoundedQueue is a bounded Queue whose elements each carry a
+// deadline, for shedding requests which went stale while waiting. Elements
+// past their deadline are silently dropped once they reach the head, by Pop,
+// or by Push to make room. Deadlines need not be in order, so an expired
+// element behind a live one is only dropped once the live one is popped.
+type ExpiringBoundedQueue[T comparable] struct {
+	q       *Queue[deadlineEntry[T]]
+	expired int
+	// now returns the current time, it is replaced by tests with a fake clock.
+	now func() time.Time
+}
+
+// NewExpiringBoundedQueue constructs and returns a new ExpiringBoundedQueue
+// holding at most capacity elements. This call panics if capacity is not
+// positive.
+func NewExpiringBoundedQueue[T comparable](capacity int) *ExpiringBoundedQueue[T] {
+	if capacity <= 0 {
+		panic("queue: NewExpiringBoundedQueue() called with non-positive capacity")
+	}
+	return &ExpiringBoundedQueue[T]{
+		q:   NewBoundedQueue[deadlineEntry[T]](capacity),
+		now: time.Now,
+	}
+}
+
+// dropExpired drops expired elements from the head, the caller must hold the
+// write lock.
+func (e *ExpiringBoundedQueue[T]) dropExpired(now time.Time) {
+	for e.q.count > 0 && now.After(e.q.buf[e.q.head].deadline) {
+		e.q.pop()
+		e.expired++
+	}
+}
+
+// Push puts an element on the end of the queue, to be dropped if not popped
+// by deadline, and returns true. It returns false without blocking if the
+// queue is still full after dropping expired elements from the head, or if
+// deadline has already passed, in which case elem counts as expired.
+func (e *ExpiringBoundedQueue[T]) Push(elem T, deadline time.Time) bool {
+	e.q.lock.Lock()
+	defer e.q.lock.Unlock()
+	now := e.now()
+	if now.After(deadline) {
+		e.expired++
+		return false
+	}
+	e.dropExpired(now)
+	if e.q.full() {
+		return false
+	}
+	e.q.push(deadlineEntry[T]{elem: elem, deadline: deadline})
+	return true
+}
+
+// Pop removes and returns the oldest element still before its deadline and
+// true, dropping the expired ones ahead of it, or a default value and false
+// if no such element exists.
+func (e *ExpiringBoundedQueue[T]) Pop() (T, bool) {
+	e.q.lock.Lock()
+	defer e.q.lock.Unlock()
+	e.dropExpired(e.now())
+	entry, ok := e.q.pop()
+	return entry.elem, ok
+}
+
+// Expired returns the number of elements dropped for missing their deadline.
+func (e *ExpiringBoundedQueue[T]) Expired() int {
+	e.q.lock.RLock()
+	defer e.q.lock.RUnlock()
+	return e.expired
+}
+
+// Size returns the number of elements in the queue, including expired ones
+// not yet dropped.
+func (e *ExpiringBoundedQueue[T]) Size() int {
+	return e.q.Size()
+}
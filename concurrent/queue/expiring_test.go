@@ -0,0 +1,58 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExpiringBoundedQueue(t *testing.T) {
+	Convey("test ExpiringBoundedQueue", t, func() {
+		clock := time.Unix(0, 0)
+		q := NewExpiringBoundedQueue[string](3)
+		q.now = func() time.Time { return clock }
+		at := func(seconds int) time.Time { return time.Unix(int64(seconds), 0) }
+
+		So(q.Push("a", at(5)), ShouldBeTrue)
+		So(q.Push("b", at(20)), ShouldBeTrue)
+		So(q.Push("c", at(10)), ShouldBeTrue)
+
+		Convey("test ExpiringBoundedQueue live elements", func() {
+			v, ok := q.Pop()
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, "a")
+			So(q.Expired(), ShouldEqual, 0)
+		})
+
+		Convey("test ExpiringBoundedQueue drops expired elements", func() {
+			clock = at(12)
+			v, ok := q.Pop()
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, "b")
+			So(q.Expired(), ShouldEqual, 1)
+			// c expired behind b, and is dropped once it reaches the head.
+			_, ok = q.Pop()
+			So(ok, ShouldBeFalse)
+			So(q.Expired(), ShouldEqual, 2)
+			So(q.Size(), ShouldEqual, 0)
+		})
+
+		Convey("test ExpiringBoundedQueue full", func() {
+			So(q.Push("d", at(30)), ShouldBeFalse)
+			// Push makes room by dropping the expired head.
+			clock = at(6)
+			So(q.Push("d", at(30)), ShouldBeTrue)
+			So(q.Expired(), ShouldEqual, 1)
+			So(q.Size(), ShouldEqual, 3)
+		})
+
+		Convey("test ExpiringBoundedQueue push past deadline", func() {
+			q.Pop()
+			clock = at(6)
+			So(q.Push("e", at(5)), ShouldBeFalse)
+			So(q.Expired(), ShouldEqual, 1)
+			So(q.Size(), ShouldEqual, 2)
+		})
+	})
+}
@@ -0,0 +1,67 @@
+package queue
+
+import "container/heap"
+
+// mergeCursor is the position reached in one source of KWayMerge.
+type mergeCursor[T comparable] struct {
+	items []T
+	src   int
+}
+
+// mergeHeap orders cursors by their current front, breaking ties by source
+// so that the merge is stable.
+type mergeHeap[T comparable] struct {
+	cursors []mergeCursor[T]
+	less    func(a, b T) bool
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.cursors) }
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	a, b := h.cursors[i], h.cursors[j]
+	if h.less(a.items[0], b.items[0]) {
+		return true
+	}
+	if h.less(b.items[0], a.items[0]) {
+		return false
+	}
+	return a.src < b.src
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) { h.cursors[i], h.cursors[j] = h.cursors[j], h.cursors[i] }
+
+func (h *mergeHeap[T]) Push(x any) { h.cursors = append(h.cursors, x.(mergeCursor[T])) }
+
+func (h *mergeHeap[T]) Pop() any {
+	c := h.cursors[len(h.cursors)-1]
+	h.cursors = h.cursors[:len(h.cursors)-1]
+	return c
+}
+
+// KWayMerge merges queues which are each sorted by less into a new queue
+// sorted by less, keeping a heap over the fronts of the sources, in
+// O(n log k). Equal elements keep the order of their sources in qs. Each
+// source is snapshotted in turn under its own read lock and left untouched;
+// a source which is not sorted yields an unsorted result.
+func KWayMerge[T comparable](less func(a, b T) bool, qs ...*Queue[T]) *Queue[T] {
+	h := &mergeHeap[T]{less: less}
+	total := 0
+	for i, q := range qs {
+		if items := q.Items(); len(items) > 0 {
+			h.cursors = append(h.cursors, mergeCursor[T]{items: items, src: i})
+			total += len(items)
+		}
+	}
+	heap.Init(h)
+	merged := make([]T, 0, total)
+	for h.Len() > 0 {
+		c := &h.cursors[0]
+		merged = append(merged, c.items[0])
+		if c.items = c.items[1:]; len(c.items) == 0 {
+			heap.Pop(h)
+		} else {
+			heap.Fix(h, 0)
+		}
+	}
+	return NewQueueFromSlice(merged)
+}
@@ -0,0 +1,42 @@
+package queue
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestKWayMerge(t *testing.T) {
+	Convey("test KWayMerge", t, func() {
+		less := func(a, b int) bool { return a < b }
+		qa := NewQueueFromSlice([]int{1, 4, 7, 10})
+		qb := NewQueueFromSlice([]int{2, 5, 8})
+		qc := NewQueue[int]()
+		// Wrap qc around its buffer.
+		for i := 0; i < 12; i++ {
+			qc.Push(-1)
+			qc.Pop()
+		}
+		for _, v := range []int{0, 0, 3, 6, 9, 11, 12} {
+			qc.Push(v)
+		}
+		So(qc.Items(), ShouldResemble, []int{0, 0, 3, 6, 9, 11, 12})
+
+		merged := KWayMerge(less, qa, qb, qc)
+		So(merged.Items(), ShouldResemble, []int{0, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+		So(qa.Size()+qb.Size()+qc.Size(), ShouldEqual, 14)
+
+		Convey("test KWayMerge is stable", func() {
+			type item struct{ key, src int }
+			byKey := func(a, b item) bool { return a.key < b.key }
+			q1 := NewQueueFromSlice([]item{{1, 1}, {2, 1}})
+			q2 := NewQueueFromSlice([]item{{1, 2}, {2, 2}})
+			So(KWayMerge(byKey, q2, q1).Items(), ShouldResemble, []item{{1, 2}, {1, 1}, {2, 2}, {2, 1}})
+		})
+
+		Convey("test KWayMerge empty", func() {
+			So(KWayMerge(less).Empty(), ShouldBeTrue)
+			So(KWayMerge(less, NewQueue[int]()).Empty(), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,53 @@
+package queue
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPooledQueue(t *testing.T) {
+	type event struct {
+		id      int
+		payload [4]int
+	}
+
+	Convey("test PooledQueue", t, func() {
+		Convey("test PooledQueue Acquire returns zeroed values", func() {
+			p := NewPooledQueue[event]()
+			v := p.Acquire()
+			v.id = 7
+			p.Release(v)
+			for i := 0; i < 10; i++ {
+				So(*p.Acquire(), ShouldResemble, event{})
+			}
+		})
+
+		Convey("test PooledQueue no corruption across cycles", func() {
+			p := NewPooledQueue[event]()
+			const producers, n = 4, 20000
+			var wg sync.WaitGroup
+			wg.Add(producers)
+			for g := 0; g < producers; g++ {
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < n; i++ {
+						v := p.Acquire()
+						id := g*n + i
+						*v = event{id: id, payload: [4]int{id, id, id, id}}
+						p.Push(v)
+						if v, ok := p.Pop(); ok {
+							if v.payload != [4]int{v.id, v.id, v.id, v.id} {
+								t.Error("Invalid result:", *v)
+							}
+							p.Release(v)
+						}
+					}
+				}(g)
+			}
+			wg.Wait()
+			So(p.Size(), ShouldEqual, 0)
+		})
+	})
+}
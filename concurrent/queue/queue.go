@@ -6,42 +6,219 @@ The queue implemented here is as fast as it is for an additional reason: it is *
 */
 package queue
 
-import "sync"
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
 
 // minQueueLen is smallest capacity that queue may have.
 // Must be power of 2 for bitwise modulus: x % n == x & (n - 1).
 const minQueueLen = 16
 
+// maxStringLen is the number of elements String renders before truncating.
+const maxStringLen = 64
+
+var (
+	// ErrTimeout is returned by Poll when no element arrives in time.
+	ErrTimeout = errors.New("queue: poll timed out")
+	// ErrClosed is returned by Poll and PopWait once the queue is closed and drained.
+	ErrClosed = errors.New("queue: queue closed")
+)
+
 // Queue represents a single instance of the queue data structure.
 type Queue[T comparable] struct {
 	buf               []T
 	head, tail, count int
 	lock              sync.RWMutex
+	// size mirrors count, and is only stored under the write lock, so that
+	// Size needs no lock.
+	size atomic.Int64
+
+	// capacity bounds count for queues built by NewBoundedQueue, zero means unbounded.
+	capacity int
+	// notFull is signalled whenever a bounded queue frees a slot.
+	notFull *sync.Cond
+	// notEmpty is signalled whenever an element is added.
+	notEmpty *sync.Cond
+	// closed is set by Close.
+	closed bool
+	// onResize is called on every reallocation of buf, see SetOnResize.
+	onResize func(oldCap, newCap int)
+	// journal mirrors pushed elements, see SetJournal. journalErr is the
+	// first encoding error, after which journaling stops.
+	journal    *gob.Encoder
+	journalErr error
+	// growthFactor is the factor set by SetGrowthFactor, zero means 2.
+	growthFactor float64
+	// noShrink disables resizing down in Pop.
+	noShrink bool
+	// minCap is the buffer size set by NewQueueWithCapacity, below which Pop
+	// never shrinks; zero means minQueueLen.
+	minCap int
+	// adaptive state, see SetAdaptive. The peak count is tracked over the
+	// current and the previous window of pops.
+	adaptive           bool
+	window, windowPops int
+	peak, previousPeak int
+	// maxCount is the high-water mark of count, see HighWaterMark.
+	maxCount int
+	// wait instrumentation, see SetWaitStats.
+	waitStats                  bool
+	producerWait, consumerWait time.Duration
+	waitSamples                int64
+	// watermark state, see SetWatermarks. aboveHigh is set once count reaches
+	// high and cleared once it falls back to low. Crossings are queued under
+	// the lock in pendingMarks and run by fireWatermarks after unlocking.
+	high, low       int
+	onHigh, onLow   func()
+	aboveHigh       bool
+	pendingMarks    []func()
+	hasPendingMarks atomic.Bool
+	// firing is held by the goroutine running queued watermark callbacks.
+	firing sync.Mutex
+	// replay records the most recently popped elements, see
+	// EnableReplayBuffer. It is only accessed under the queue lock.
+	replay *RingLog[T]
 }
 
 // NewQueue constructs and returns a new Queue.
 func NewQueue[T comparable]() *Queue[T] {
-	return &Queue[T]{
+	q := &Queue[T]{
 		buf: make([]T, minQueueLen),
 	}
+	q.notEmpty = sync.NewCond(&q.lock)
+	return q
+}
+
+// NewBoundedQueue constructs and returns a new Queue holding at most capacity
+// elements. Push blocks while a bounded queue is full; use PushWait to give up
+// on cancellation. This call panics if capacity is not positive.
+func NewBoundedQueue[T comparable](capacity int) *Queue[T] {
+	if capacity <= 0 {
+		panic("queue: NewBoundedQueue() called with non-positive capacity")
+	}
+	q := &Queue[T]{
+		buf:      make([]T, minQueueLen),
+		capacity: capacity,
+	}
+	q.notFull = sync.NewCond(&q.lock)
+	q.notEmpty = sync.NewCond(&q.lock)
+	return q
+}
+
+// NewQueueWithCapacity constructs and returns a new, unbounded Queue whose
+// buffer can hold capacity elements before it has to grow. The buffer is
+// never shrunk below that size again, so the preallocation survives the
+// queue draining. This call panics if capacity is negative.
+func NewQueueWithCapacity[T comparable](capacity int) *Queue[T] {
+	if capacity < 0 {
+		panic("queue: NewQueueWithCapacity() called with negative capacity")
+	}
+	size := nextPow2(capacity)
+	q := &Queue[T]{
+		buf:    make([]T, size),
+		minCap: size,
+	}
+	q.notEmpty = sync.NewCond(&q.lock)
+	return q
+}
+
+// NewQueueFromSlice constructs a new Queue holding a copy of items, with
+// items[0] at the head of the queue.
+func NewQueueFromSlice[T comparable](items []T) *Queue[T] {
+	size := nextPow2(len(items))
+	buf := make([]T, size)
+	copy(buf, items)
+	q := &Queue[T]{
+		buf: buf,
+		// bitwise modulus
+		tail:     len(items) & (size - 1),
+		count:    len(items),
+		maxCount: len(items),
+	}
+	q.size.Store(int64(len(items)))
+	q.notEmpty = sync.NewCond(&q.lock)
+	return q
 }
 
-// Size returns the number of elements currently stored in the queue.
+// Size returns the number of elements currently stored in the queue. It is a
+// single atomic load and never waits for the lock.
 func (q *Queue[T]) Size() int {
-	q.lock.RLock()
-	count := q.count
-	q.lock.RUnlock()
-	return count
+	return int(q.size.Load())
 }
 
 func (q *Queue[T]) Empty() bool {
 	return q.Size() == 0
 }
 
-// resizes the queue to fit exactly twice its current contents
+// nextPow2 returns the buffer size for n elements: the smallest power of 2 no
+// smaller than n, and at least minQueueLen. Every buffer size must come from
+// here, or from doubling one that did, for the bitwise modulus to hold.
+func nextPow2(n int) int {
+	size := minQueueLen
+	for size < n {
+		size <<= 1
+	}
+	return size
+}
+
+// Reserve grows the buffer, if needed, so that n more elements can be pushed
+// without reallocating. A later Pop may still shrink it again unless shrinking
+// is disabled by SetShrinkPolicy.
+func (q *Queue[T]) Reserve(n int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if n > 0 && q.count+n > len(q.buf) {
+		q.resizeTo(nextPow2(q.count + n))
+	}
+}
+
+// resizes the queue to fit exactly twice its current contents, or the growth
+// factor times them rounded up to a power of 2
 // this can result in shrinking if the queue is less than half-full
 func (q *Queue[T]) resize() {
-	newBuf := make([]T, q.count<<1)
+	if q.growthFactor != 0 {
+		q.resizeTo(nextPow2(int(math.Ceil(float64(q.count) * q.growthFactor))))
+		return
+	}
+	q.resizeTo(q.count << 1)
+}
+
+// SetGrowthFactor sets the factor by which a full buffer grows, 2 by default.
+// The new size is rounded up to a power of 2 for the bitwise modulus, so every
+// factor up to 2 still doubles the buffer; only larger factors, trading memory
+// for fewer reallocations, make a difference. To bound memory use, size the
+// queue up front with NewQueueWithCapacity or Reserve instead. This call
+// panics if f is not greater than 1.
+func (q *Queue[T]) SetGrowthFactor(f float64) {
+	if !(f > 1) {
+		panic("queue: SetGrowthFactor() called with factor not greater than 1")
+	}
+	q.lock.Lock()
+	q.growthFactor = f
+	if f == 2 {
+		q.growthFactor = 0
+	}
+	q.lock.Unlock()
+}
+
+// resizeTo moves the contents into a new buffer of the given size, which must
+// be a power of 2 larger than the current count. The resize hook, if any, is
+// called before returning.
+func (q *Queue[T]) resizeTo(size int) {
+	newBuf := make([]T, size)
 
 	if q.tail > q.head {
 		copy(newBuf, q.buf[q.head:q.tail])
@@ -50,25 +227,548 @@ func (q *Queue[T]) resize() {
 		copy(newBuf[n:], q.buf[:q.tail])
 	}
 
+	oldCap := len(q.buf)
 	q.head = 0
 	q.tail = q.count
 	q.buf = newBuf
+	if q.onResize != nil {
+		q.onResize(oldCap, size)
+	}
 }
 
-// Push puts an element on the end of the queue.
-func (q *Queue[T]) Push(elem T) {
+// SetOnResize installs fn to be called with the old and new buffer sizes
+// whenever the buffer is reallocated; newCap > oldCap means it grew. fn runs
+// under the write lock, so it must be quick and must not call back into the
+// queue. Passing nil removes the hook.
+func (q *Queue[T]) SetOnResize(fn func(oldCap, newCap int)) {
+	q.lock.Lock()
+	q.onResize = fn
+	q.lock.Unlock()
+}
+
+// SetWatermarks installs back-pressure callbacks: onHigh runs when the queue
+// grows to high elements, and onLow runs when it then shrinks back to low.
+// Neither fires again until the other has, so a queue hovering around one
+// watermark does not flap. Either callback may be nil. A queue already at or
+// above high when this is called counts as above it, without firing onHigh.
+// This call panics unless 0 <= low < high.
+//
+// Callbacks run outside the lock, so they may use the queue, but not
+// necessarily on the goroutine whose call crossed the watermark: crossings
+// are queued, and whichever mutating call returns next runs the queued
+// callbacks. They run one at a time, in the order the watermarks were
+// crossed, so onHigh and onLow always alternate. A crossing caused by a
+// callback itself runs after that callback returns.
+func (q *Queue[T]) SetWatermarks(high, low int, onHigh, onLow func()) {
+	if low < 0 || low >= high {
+		panic("queue: SetWatermarks() called with invalid watermarks")
+	}
 	q.lock.Lock()
+	q.high, q.low = high, low
+	q.onHigh, q.onLow = onHigh, onLow
+	q.aboveHigh = q.count >= high
+	q.lock.Unlock()
+}
+
+// checkWatermarks queues the callback for a watermark crossed by the current
+// count, the caller must hold the write lock.
+func (q *Queue[T]) checkWatermarks() {
+	if q.high == 0 {
+		return
+	}
+	var fn func()
+	if !q.aboveHigh && q.count >= q.high {
+		q.aboveHigh, fn = true, q.onHigh
+	} else if q.aboveHigh && q.count <= q.low {
+		q.aboveHigh, fn = false, q.onLow
+	}
+	if fn != nil {
+		q.pendingMarks = append(q.pendingMarks, fn)
+		q.hasPendingMarks.Store(true)
+	}
+}
+
+// fireWatermarks runs the queued watermark callbacks, the caller must not
+// hold the lock. Only one goroutine runs callbacks at a time; the others
+// leave theirs to it. It checks for callbacks again after handing over, as
+// one queued meanwhile may have found it still running.
+func (q *Queue[T]) fireWatermarks() {
+	for q.hasPendingMarks.Load() {
+		if !q.firing.TryLock() {
+			return
+		}
+		q.runWatermarks()
+	}
+}
+
+// runWatermarks runs queued watermark callbacks until none is left, the
+// caller must hold firing, which is released on return.
+func (q *Queue[T]) runWatermarks() {
+	defer q.firing.Unlock()
+	for {
+		q.lock.Lock()
+		fns := q.pendingMarks
+		q.pendingMarks = nil
+		q.hasPendingMarks.Store(false)
+		q.lock.Unlock()
+		if len(fns) == 0 {
+			return
+		}
+		for _, fn := range fns {
+			fn()
+		}
+	}
+}
+
+// EnableReplayBuffer makes the queue remember the last n elements removed
+// from its front, by Pop or any other popping call, for RecentlyPopped.
+// Calling it again discards what was recorded so far, and n == 0 turns the
+// buffer off, which is the default. This call panics if n is negative.
+func (q *Queue[T]) EnableReplayBuffer(n int) {
+	if n < 0 {
+		panic("queue: EnableReplayBuffer() called with negative size")
+	}
+	q.lock.Lock()
+	q.replay = nil
+	if n > 0 {
+		q.replay = NewRingLog[T](n)
+	}
+	q.lock.Unlock()
+}
+
+// RecentlyPopped returns a copy of the elements recorded by the replay
+// buffer, oldest first, or nil if the buffer is off.
+func (q *Queue[T]) RecentlyPopped() []T {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	if q.replay == nil {
+		return nil
+	}
+	return q.replay.ToSlice()
+}
+
+// SetShrinkPolicy enables or disables resizing the buffer down once it becomes
+// a quarter full. Shrinking is enabled by default; disabling it avoids repeated
+// reallocation for workloads oscillating around that boundary, at the cost of
+// keeping the largest buffer ever needed.
+func (q *Queue[T]) SetShrinkPolicy(enabled bool) {
+	q.lock.Lock()
+	q.noShrink = !enabled
+	q.lock.Unlock()
+}
+
+// SetAdaptive enables or disables adaptive shrinking. When enabled, the queue
+// remembers its peak size over the last window to 2*window pops and does not
+// shrink to a buffer too small to hold that peak again, which saves repeated
+// shrinking and re-growth for queues that spike and drain. It is off by default.
+func (q *Queue[T]) SetAdaptive(enabled bool, window int) {
+	q.lock.Lock()
+	q.adaptive = enabled
+	q.window = window
+	q.windowPops = 0
+	q.peak, q.previousPeak = q.count, 0
+	q.lock.Unlock()
+}
+
+// minLen returns the smallest buffer size the queue shrinks to.
+func (q *Queue[T]) minLen() int {
+	return max(minQueueLen, q.minCap)
+}
+
+// shrinkSize returns the buffer size Pop should resize down to, or zero if
+// the buffer should be kept.
+func (q *Queue[T]) shrinkSize() int {
+	// Resize down once buffer is at most 1/4 full. Checking for exactly 1/4
+	// would miss the boundary whenever the count skips past it.
+	if q.noShrink || len(q.buf) <= q.minLen() || (q.count<<2) > len(q.buf) {
+		return 0
+	}
+	// Shrink to the smallest buffer left at most half full which, in adaptive
+	// mode, can also hold the recent peak.
+	need := q.count << 1
+	if q.adaptive {
+		need = max(need, q.peak, q.previousPeak)
+	}
+	size := max(nextPow2(need), q.minLen())
+	if size >= len(q.buf) {
+		return 0
+	}
+	return size
+}
+
+// trackPeak updates the adaptive peak after a push, with pops == 0, or after
+// pops elements were popped. The caller must hold the write lock.
+func (q *Queue[T]) trackPeak(pops int) {
+	if !q.adaptive {
+		return
+	}
+	if q.count > q.peak {
+		q.peak = q.count
+	}
+	if pops == 0 {
+		return
+	}
+	q.windowPops += pops
+	if q.windowPops >= q.window {
+		q.previousPeak, q.peak = q.peak, q.count
+		q.windowPops = 0
+	}
+}
+
+// full reports whether a bounded queue has no free slot.
+func (q *Queue[T]) full() bool {
+	return q.capacity > 0 && q.count >= q.capacity
+}
+
+// push puts an element on the end of the queue, the caller must hold the write lock.
+func (q *Queue[T]) push(elem T) {
 	if q.count == len(q.buf) {
 		q.resize()
 	}
 
+	if q.journal != nil && q.journalErr == nil {
+		q.journalErr = q.journal.Encode(elem)
+	}
 	q.buf[q.tail] = elem
 	// bitwise modulus
 	q.tail = (q.tail + 1) & (len(q.buf) - 1)
 	q.count++
+	q.size.Store(int64(q.count))
+	q.checkWatermarks()
+	if q.count > q.maxCount {
+		q.maxCount = q.count
+	}
+	q.trackPeak(0)
+	q.notEmpty.Signal()
+}
+
+// Push puts an element on the end of the queue. On a bounded queue this call
+// blocks until a slot is free.
+func (q *Queue[T]) Push(elem T) {
+	q.lock.Lock()
+	q.waitNotFull()
+	q.push(elem)
+	q.lock.Unlock()
+	q.fireWatermarks()
+}
+
+// SetJournal mirrors every element pushed from now on to w, gob-encoded, so
+// that ReplayQueue can rebuild the queue from the log. Elements put back at
+// the head, e.g. by a WithBatch rollback, were journaled when first pushed and
+// are not written again. Pops are never journaled: replaying restores every
+// element ever pushed, including those since popped, so recovery is
+// at-least-once and consumers must tolerate redelivery.
+//
+// Each call starts a new gob stream, which ReplayQueue must read separately;
+// to keep journaling after a replay, write to a fresh log. The first write or
+// encoding error stops journaling and is reported by JournalErr. Passing nil
+// stops journaling.
+func (q *Queue[T]) SetJournal(w io.Writer) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.journal, q.journalErr = nil, nil
+	if w != nil {
+		q.journal = gob.NewEncoder(w)
+	}
+}
+
+// JournalErr returns the error which stopped journaling, if any.
+func (q *Queue[T]) JournalErr() error {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	return q.journalErr
+}
+
+// ReplayQueue constructs a new Queue holding, in order, every element written
+// to r by a journal, see SetJournal. It returns the elements decoded so far
+// along with any error other than reaching the end of r.
+func ReplayQueue[T comparable](r io.Reader) (*Queue[T], error) {
+	q := NewQueue[T]()
+	dec := gob.NewDecoder(r)
+	for {
+		var elem T
+		if err := dec.Decode(&elem); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return q, err
+		}
+		q.push(elem)
+	}
+}
+
+// TryPush puts an element on the end of the queue and returns true, unless it
+// would have to block: it returns false without pushing if the lock is held
+// elsewhere or a bounded queue is full.
+func (q *Queue[T]) TryPush(elem T) bool {
+	if !q.lock.TryLock() {
+		return false
+	}
+	defer q.fireWatermarks()
+	defer q.lock.Unlock()
+	if q.full() {
+		return false
+	}
+	q.push(elem)
+	return true
+}
+
+// PushIndexed behaves like Push and returns the index the element occupies
+// right after insertion, i.e. Size()-1 at that time. The index is only valid
+// until the next mutation of the queue, as a Pop shifts every index down.
+func (q *Queue[T]) PushIndexed(elem T) int {
+	q.lock.Lock()
+	defer q.fireWatermarks()
+	defer q.lock.Unlock()
+	q.waitNotFull()
+	q.push(elem)
+	return q.count - 1
+}
+
+// waitNotFull blocks while a bounded queue is full, the caller must hold the
+// write lock.
+func (q *Queue[T]) waitNotFull() {
+	if !q.full() {
+		return
+	}
+	start := q.waitStart()
+	for q.full() {
+		q.notFull.Wait()
+	}
+	q.recordWait(start, &q.producerWait)
+}
+
+// SetWaitStats enables or disables recording how long producers wait for a
+// free slot and consumers wait for an element, as reported by WaitStats. It is
+// off by default, so that blocking calls do not read the clock.
+func (q *Queue[T]) SetWaitStats(enabled bool) {
+	q.lock.Lock()
+	q.waitStats = enabled
+	q.lock.Unlock()
+}
+
+// WaitStats returns the total time producers waited for a free slot and
+// consumers waited for an element, and the number of waits recorded, while
+// wait stats were enabled.
+func (q *Queue[T]) WaitStats() (producerWait, consumerWait time.Duration, samples int64) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	return q.producerWait, q.consumerWait, q.waitSamples
+}
+
+// HighWaterMark returns the largest number of elements the queue has held
+// since it was built or since the last ResetHighWaterMark.
+func (q *Queue[T]) HighWaterMark() int {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	return q.maxCount
+}
+
+// ResetHighWaterMark restarts high-water mark tracking from the current size,
+// e.g. at the start of each metrics reporting interval.
+func (q *Queue[T]) ResetHighWaterMark() {
+	q.lock.Lock()
+	q.maxCount = q.count
 	q.lock.Unlock()
 }
 
+// waitStart returns the start time of a wait, or the zero time if wait stats
+// are disabled.
+func (q *Queue[T]) waitStart() time.Time {
+	if !q.waitStats {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// recordWait adds the time elapsed since start to total, the caller must hold
+// the write lock.
+func (q *Queue[T]) recordWait(start time.Time, total *time.Duration) {
+	if start.IsZero() {
+		return
+	}
+	*total += time.Since(start)
+	q.waitSamples++
+}
+
+// PushSeq puts every element yielded by seq on the end of the queue, in order,
+// holding the write lock once. The buffer grows geometrically as elements
+// arrive. On a bounded queue it waits for a free slot before each element,
+// so other producers may interleave while it waits.
+func (q *Queue[T]) PushSeq(seq iter.Seq[T]) {
+	q.lock.Lock()
+	defer q.fireWatermarks()
+	defer q.lock.Unlock()
+	for elem := range seq {
+		q.waitNotFull()
+		q.push(elem)
+	}
+}
+
+// PushWait puts an element on the end of the queue, blocking while a bounded
+// queue is full. It returns ctx.Err() without pushing if ctx is done first.
+func (q *Queue[T]) PushWait(ctx context.Context, elem T) error {
+	q.lock.Lock()
+	defer q.fireWatermarks()
+	defer q.lock.Unlock()
+	if q.full() {
+		defer q.recordWait(q.waitStart(), &q.producerWait)
+		defer q.wakeOnDone(ctx, q.notFull)()
+		for q.full() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			q.notFull.Wait()
+		}
+	}
+	q.push(elem)
+	return nil
+}
+
+// PopWait removes and returns the element from the front of the queue,
+// blocking while the queue is empty. It returns ctx.Err() if ctx is done first,
+// or ErrClosed if the queue is closed and empty.
+func (q *Queue[T]) PopWait(ctx context.Context) (T, error) {
+	q.lock.Lock()
+	defer q.fireWatermarks()
+	defer q.lock.Unlock()
+	if q.count <= 0 && !q.closed {
+		defer q.recordWait(q.waitStart(), &q.consumerWait)
+		defer q.wakeOnDone(ctx, q.notEmpty)()
+		for q.count <= 0 && !q.closed {
+			if err := ctx.Err(); err != nil {
+				var v T
+				return v, err
+			}
+			q.notEmpty.Wait()
+		}
+	}
+	if q.count <= 0 {
+		var v T
+		return v, ErrClosed
+	}
+	v, _ := q.pop()
+	return v, nil
+}
+
+// Poll removes and returns the element from the front of the queue, waiting
+// up to timeout while the queue is empty. It returns ErrTimeout if nothing
+// arrives in time, or ErrClosed if the queue is closed and empty. A
+// non-positive timeout does not wait.
+func (q *Queue[T]) Poll(timeout time.Duration) (T, error) {
+	q.lock.Lock()
+	defer q.fireWatermarks()
+	defer q.lock.Unlock()
+	if q.count <= 0 && !q.closed && timeout > 0 {
+		defer q.recordWait(q.waitStart(), &q.consumerWait)
+		// The timer sets expired under the lock, so it cannot slip in between
+		// the check below and Wait.
+		expired := false
+		timer := time.AfterFunc(timeout, func() {
+			q.lock.Lock()
+			expired = true
+			q.notEmpty.Broadcast()
+			q.lock.Unlock()
+		})
+		defer timer.Stop()
+		for q.count <= 0 && !q.closed && !expired {
+			q.notEmpty.Wait()
+		}
+	}
+	if q.count <= 0 {
+		var v T
+		if q.closed {
+			return v, ErrClosed
+		}
+		return v, ErrTimeout
+	}
+	v, _ := q.pop()
+	return v, nil
+}
+
+// Close marks the queue as closed and wakes every Poll and PopWait waiting on
+// it. Both keep returning the remaining elements and report ErrClosed once the
+// queue is empty, which also ends Channel and Batcher.Batches. Close does not
+// stop pushes, producers should stop before closing.
+func (q *Queue[T]) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+}
+
+// wakeOnDone broadcasts cond once ctx is done, until the returned function is
+// called. The broadcast is made under the lock so that it cannot slip in
+// between a waiter checking ctx and calling Wait.
+func (q *Queue[T]) wakeOnDone(ctx context.Context, cond *sync.Cond) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			q.lock.Lock()
+			cond.Broadcast()
+			q.lock.Unlock()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+// Channel starts a goroutine moving elements from the queue to the returned
+// channel, which has the given buffer size, using PopWait. Once ctx is done
+// the goroutine puts back at the head any element it has popped but not yet
+// delivered, closes the channel and exits. The channel is also closed once the
+// queue is closed and drained. Elements already buffered in the channel stay
+// there. The goroutine competes with any other consumer, so the
+// queue should not be popped elsewhere while the channel is in use.
+func (q *Queue[T]) Channel(ctx context.Context, buf int) <-chan T {
+	ch := make(chan T, buf)
+	go func() {
+		defer close(ch)
+		for {
+			v, err := q.PopWait(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case ch <- v:
+			case <-ctx.Done():
+				q.lock.Lock()
+				q.pushFront(v)
+				q.lock.Unlock()
+				q.fireWatermarks()
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// FromChannel starts a goroutine pushing every value received on src onto
+// the queue, using PushWait so that a bounded queue applies back-pressure.
+// The goroutine exits once src is closed and drained or ctx is done, and
+// closes the returned channel when it does.
+func (q *Queue[T]) FromChannel(ctx context.Context, src <-chan T) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case v, ok := <-src:
+				if !ok {
+					return
+				}
+				if q.PushWait(ctx, v) != nil {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return done
+}
+
 // Peek returns the element at the head of the queue. This call panics
 // if the queue is empty.
 func (q *Queue[T]) Peek() T {
@@ -82,32 +782,291 @@ func (q *Queue[T]) Peek() T {
 	return v
 }
 
+// Front returns the element at the head of the queue and true, or a default
+// value and false if the queue is empty. Unlike Peek it never panics.
+func (q *Queue[T]) Front() (T, bool) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	if q.count <= 0 {
+		var v T
+		return v, false
+	}
+	return q.buf[q.head], true
+}
+
+// Back returns the element at the tail of the queue and true, or a default
+// value and false if the queue is empty.
+func (q *Queue[T]) Back() (T, bool) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	if q.count <= 0 {
+		var v T
+		return v, false
+	}
+	// bitwise modulus
+	return q.buf[(q.tail-1)&(len(q.buf)-1)], true
+}
+
+// PeekN returns a copy of up to the first k elements of the queue, head
+// first, without removing them. Fewer are returned if the queue is shorter.
+func (q *Queue[T]) PeekN(k int) []T {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	if k > q.count {
+		k = q.count
+	}
+	if k <= 0 {
+		return []T{}
+	}
+	items := make([]T, k)
+	n := copy(items, q.buf[q.head:])
+	copy(items[n:], q.buf)
+	return items
+}
+
+// PeekTailN returns a copy of up to the last k elements of the queue, in
+// head to tail order, without removing them. Fewer are returned if the queue
+// is shorter.
+func (q *Queue[T]) PeekTailN(k int) []T {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	if k > q.count {
+		k = q.count
+	}
+	if k <= 0 {
+		return []T{}
+	}
+	items := make([]T, k)
+	// bitwise modulus
+	start := (q.tail - k) & (len(q.buf) - 1)
+	n := copy(items, q.buf[start:])
+	copy(items[n:], q.buf)
+	return items
+}
+
+// CopyTo copies up to len(dst) elements, head first, into dst and returns
+// how many were copied. Unlike Items it does not allocate.
+func (q *Queue[T]) CopyTo(dst []T) int {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	if len(dst) > q.count {
+		dst = dst[:q.count]
+	}
+	n := copy(dst, q.buf[q.head:])
+	n += copy(dst[n:], q.buf)
+	return n
+}
+
 // Get returns the element at index i in the queue. If the index is
 // invalid, the call will panic. This method accepts both positive and
 // negative index values. Index 0 refers to the first element, and
 // index -1 refers to the last.
 func (q *Queue[T]) Get(i int) T {
 	q.lock.RLock()
+	p, ok := q.physicalIndex(i)
+	if !ok {
+		q.lock.RUnlock()
+		panic("queue: Get() called with index out of range")
+	}
+	v := q.buf[p]
+	q.lock.RUnlock()
+	return v
+}
+
+// Set replaces the element at index i in the queue with val. Like Get, it
+// accepts negative indices and panics if the index is invalid.
+func (q *Queue[T]) Set(i int, val T) {
+	q.lock.Lock()
+	p, ok := q.physicalIndex(i)
+	if !ok {
+		q.lock.Unlock()
+		panic("queue: Set() called with index out of range")
+	}
+	q.buf[p] = val
+	q.lock.Unlock()
+}
+
+// Swap exchanges the elements at indices i and j in the queue. Like Get, it
+// accepts negative indices and panics if either index is invalid.
+func (q *Queue[T]) Swap(i, j int) {
+	q.lock.Lock()
+	pi, iok := q.physicalIndex(i)
+	pj, jok := q.physicalIndex(j)
+	if !iok || !jok {
+		q.lock.Unlock()
+		panic("queue: Swap() called with index out of range")
+	}
+	q.buf[pi], q.buf[pj] = q.buf[pj], q.buf[pi]
+	q.lock.Unlock()
+}
+
+// Shuffle randomizes the order of the elements in place with a Fisher-Yates
+// shuffle drawing from r, so a seeded r gives a reproducible order.
+func (q *Queue[T]) Shuffle(r *rand.Rand) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	mask := len(q.buf) - 1
+	for i := q.count - 1; i > 0; i-- {
+		j := r.Intn(i + 1)
+		// bitwise modulus
+		pi, pj := (q.head+i)&mask, (q.head+j)&mask
+		q.buf[pi], q.buf[pj] = q.buf[pj], q.buf[pi]
+	}
+}
+
+// Compact moves the contents in place so that they start at the beginning of
+// the buffer and no longer wrap around its end, without changing capacity or
+// order. It does nothing if the head is already at the beginning.
+func (q *Queue[T]) Compact() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.head == 0 {
+		return
+	}
+	// Rotate the whole buffer left by head with three reversals.
+	slices.Reverse(q.buf[:q.head])
+	slices.Reverse(q.buf[q.head:])
+	slices.Reverse(q.buf)
+	q.head = 0
+	// bitwise modulus
+	q.tail = q.count & (len(q.buf) - 1)
+}
+
+// Reverse reverses the order of the elements in place, so the tail becomes
+// the head.
+func (q *Queue[T]) Reverse() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	mask := len(q.buf) - 1
+	for i, j := 0, q.count-1; i < j; i, j = i+1, j-1 {
+		// bitwise modulus
+		pi, pj := (q.head+i)&mask, (q.head+j)&mask
+		q.buf[pi], q.buf[pj] = q.buf[pj], q.buf[pi]
+	}
+}
+
+// MoveToFront moves the element at index i to the head of the queue,
+// preserving the order of the other elements. Like Get, it accepts negative
+// indices and panics if the index is invalid.
+func (q *Queue[T]) MoveToFront(i int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	p, ok := q.physicalIndex(i)
+	if !ok {
+		panic("queue: MoveToFront() called with index out of range")
+	}
+	mask := len(q.buf) - 1
+	v := q.buf[p]
+	for ; p != q.head; p = (p - 1) & mask {
+		q.buf[p] = q.buf[(p-1)&mask]
+	}
+	q.buf[p] = v
+}
+
+// MoveToBack moves the element at index i to the tail of the queue,
+// preserving the order of the other elements. Like Get, it accepts negative
+// indices and panics if the index is invalid.
+func (q *Queue[T]) MoveToBack(i int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	p, ok := q.physicalIndex(i)
+	if !ok {
+		panic("queue: MoveToBack() called with index out of range")
+	}
+	mask := len(q.buf) - 1
+	last := (q.tail - 1) & mask
+	v := q.buf[p]
+	for ; p != last; p = (p + 1) & mask {
+		q.buf[p] = q.buf[(p+1)&mask]
+	}
+	q.buf[p] = v
+}
+
+// physicalIndex translates the index i, which may be negative, into a slot of
+// buf. It reports false if i is out of range. The caller must hold the lock.
+//
+// Any int is safe: i is range checked before it reaches the slot arithmetic,
+// and adding count to a negative i cannot overflow.
+func (q *Queue[T]) physicalIndex(i int) (int, bool) {
 	// If indexing backwards, convert to positive index.
 	if i < 0 {
 		i += q.count
 	}
 	if i < 0 || i >= q.count {
-		q.lock.RUnlock()
-		panic("queue: Get() called with index out of range")
+		return 0, false
 	}
 	// bitwise modulus
-	v := q.buf[(q.head+i)&(len(q.buf)-1)]
-	q.lock.RUnlock()
-	return v
+	return (q.head + i) & (len(q.buf) - 1), true
 }
 
 // Pop removes and returns the element from the front of the queue. If the
 // queue is empty, the call will panic.
 func (q *Queue[T]) Pop() (T, bool) {
 	q.lock.Lock()
+	v, ok := q.pop()
+	q.lock.Unlock()
+	q.fireWatermarks()
+	return v, ok
+}
+
+// TryPop behaves like Pop without blocking on the lock. If the lock is held
+// elsewhere it returns acquired=false without popping; otherwise ok reports
+// whether an element was popped.
+func (q *Queue[T]) TryPop() (val T, ok, acquired bool) {
+	if !q.lock.TryLock() {
+		return val, false, false
+	}
+	val, ok = q.pop()
+	q.lock.Unlock()
+	q.fireWatermarks()
+	return val, ok, true
+}
+
+// PopN removes and returns up to n elements from the front of the queue,
+// head first, under a single lock. Fewer are returned if the queue is shorter.
+// The elements are moved out as one block, and the buffer is resized down at
+// most once, after the whole batch.
+func (q *Queue[T]) PopN(n int) []T {
+	q.lock.Lock()
+	defer q.fireWatermarks()
+	defer q.lock.Unlock()
+	if n > q.count {
+		n = q.count
+	}
+	if n <= 0 {
+		return []T{}
+	}
+	items := make([]T, n)
+	if end := q.head + n; end <= len(q.buf) {
+		copy(items, q.buf[q.head:end])
+	} else {
+		m := copy(items, q.buf[q.head:])
+		copy(items[m:], q.buf[:n-m])
+	}
+	// bitwise modulus
+	q.head = (q.head + n) & (len(q.buf) - 1)
+	q.count -= n
+	q.size.Store(int64(q.count))
+	q.checkWatermarks()
+	q.trackPeak(n)
+	if q.replay != nil {
+		for _, v := range items {
+			q.replay.push(v)
+		}
+	}
+	if size := q.shrinkSize(); size > 0 {
+		q.resizeTo(size)
+	}
+	if q.notFull != nil {
+		q.notFull.Broadcast()
+	}
+	return items
+}
+
+// pop removes and returns the element from the front of the queue, the caller
+// must hold the write lock.
+func (q *Queue[T]) pop() (T, bool) {
 	if q.count <= 0 {
-		q.lock.Unlock()
 		var v T
 		return v, false
 	}
@@ -116,12 +1075,196 @@ func (q *Queue[T]) Pop() (T, bool) {
 	// bitwise modulus
 	q.head = (q.head + 1) & (len(q.buf) - 1)
 	q.count--
-	// Resize down if buffer 1/4 full.
-	if len(q.buf) > minQueueLen && (q.count<<2) == len(q.buf) {
+	q.size.Store(int64(q.count))
+	q.checkWatermarks()
+	q.trackPeak(1)
+	if q.replay != nil {
+		q.replay.push(ret)
+	}
+	if size := q.shrinkSize(); size > 0 {
+		q.resizeTo(size)
+	}
+	if q.notFull != nil {
+		q.notFull.Signal()
+	}
+	return ret, true
+}
+
+// pushFront puts an element at the head of the queue, the caller must hold
+// the write lock.
+func (q *Queue[T]) pushFront(elem T) {
+	if q.count == len(q.buf) {
 		q.resize()
 	}
+
+	// bitwise modulus
+	q.head = (q.head - 1) & (len(q.buf) - 1)
+	q.buf[q.head] = elem
+	q.count++
+	q.size.Store(int64(q.count))
+	q.checkWatermarks()
+	if q.count > q.maxCount {
+		q.maxCount = q.count
+	}
+	q.trackPeak(0)
+	q.notEmpty.Signal()
+}
+
+// WithBatch pops up to max elements and passes them to fn. If fn returns an
+// error, the elements are put back at the head of the queue in their
+// original order and the error is returned; otherwise they stay consumed.
+// The lock is not held while fn runs, so other goroutines may use the queue
+// meanwhile. A rollback may take a bounded queue past its capacity.
+func (q *Queue[T]) WithBatch(max int, fn func([]T) error) error {
+	q.lock.Lock()
+	n := max
+	if n > q.count {
+		n = q.count
+	}
+	if n < 0 {
+		n = 0
+	}
+	batch := make([]T, n)
+	for i := range batch {
+		batch[i], _ = q.pop()
+	}
 	q.lock.Unlock()
-	return ret, true
+	q.fireWatermarks()
+
+	err := fn(batch)
+	if err != nil {
+		q.lock.Lock()
+		for i := len(batch) - 1; i >= 0; i-- {
+			q.pushFront(batch[i])
+		}
+		q.lock.Unlock()
+		q.fireWatermarks()
+	}
+	return err
+}
+
+// PopIf removes and returns the element at the front of the queue only if it
+// equals expected. Otherwise, or if the queue is empty, the queue is left
+// untouched and false is returned.
+func (q *Queue[T]) PopIf(expected T) (T, bool) {
+	q.lock.Lock()
+	defer q.fireWatermarks()
+	defer q.lock.Unlock()
+	if q.count <= 0 || q.buf[q.head] != expected {
+		var v T
+		return v, false
+	}
+	return q.pop()
+}
+
+// Dedup removes runs of consecutive equal elements, keeping the first element
+// of each run, and returns how many elements were removed.
+func (q *Queue[T]) Dedup() int {
+	q.lock.Lock()
+	defer q.fireWatermarks()
+	defer q.lock.Unlock()
+	if q.count <= 1 {
+		return 0
+	}
+	mask := len(q.buf) - 1
+	kept := 1
+	for i := 1; i < q.count; i++ {
+		// bitwise modulus
+		v := q.buf[(q.head+i)&mask]
+		if v != q.buf[(q.head+kept-1)&mask] {
+			q.buf[(q.head+kept)&mask] = v
+			kept++
+		}
+	}
+	removed := q.count - kept
+	var zero T
+	for i := kept; i < q.count; i++ {
+		q.buf[(q.head+i)&mask] = zero
+	}
+	q.count = kept
+	q.size.Store(int64(kept))
+	q.checkWatermarks()
+	q.tail = (q.head + kept) & mask
+	if removed > 0 && q.notFull != nil {
+		q.notFull.Broadcast()
+	}
+	return removed
+}
+
+// Rotate advances the queue by n positions so that the element at index n
+// becomes the new head. Negative values rotate the other way, so index -1
+// becomes the head for n == -1. n is taken modulo the queue size, and the
+// buffer is never reallocated.
+func (q *Queue[T]) Rotate(n int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.count <= 1 {
+		return
+	}
+	n %= q.count
+	if n < 0 {
+		n += q.count
+	}
+	if n == 0 {
+		return
+	}
+	mask := len(q.buf) - 1
+	if q.count == len(q.buf) {
+		// A full ring only needs its boundaries moved.
+		q.head = (q.head + n) & mask
+		q.tail = q.head
+		return
+	}
+
+	var zero T
+	if n <= q.count>>1 {
+		// Move the first n elements behind the tail.
+		for ; n > 0; n-- {
+			q.buf[q.tail] = q.buf[q.head]
+			q.buf[q.head] = zero
+			q.head = (q.head + 1) & mask
+			q.tail = (q.tail + 1) & mask
+		}
+	} else {
+		// Cheaper to move the last count-n elements in front of the head.
+		for n = q.count - n; n > 0; n-- {
+			q.head = (q.head - 1) & mask
+			q.tail = (q.tail - 1) & mask
+			q.buf[q.head] = q.buf[q.tail]
+			q.buf[q.tail] = zero
+		}
+	}
+}
+
+// SwapOut empties the queue in one locked operation and returns its former
+// contents, head first. The queue moves on to a fresh buffer of its minimal
+// size, see NewQueueWithCapacity, so the caller owns the returned slice; when
+// the contents did not wrap around the old buffer, the slice is that buffer
+// itself and nothing is copied.
+func (q *Queue[T]) SwapOut() []T {
+	q.lock.Lock()
+	defer q.fireWatermarks()
+	defer q.lock.Unlock()
+	var items []T
+	if end := q.head + q.count; end <= len(q.buf) {
+		items = q.buf[q.head:end:end]
+	} else {
+		items = make([]T, q.count)
+		n := copy(items, q.buf[q.head:])
+		copy(items[n:], q.buf[:q.tail])
+	}
+	oldCap, newCap := len(q.buf), q.minLen()
+	q.buf = make([]T, newCap)
+	q.head, q.tail, q.count = 0, 0, 0
+	q.size.Store(0)
+	q.checkWatermarks()
+	if q.onResize != nil && oldCap != newCap {
+		q.onResize(oldCap, newCap)
+	}
+	if q.notFull != nil {
+		q.notFull.Broadcast()
+	}
+	return items
 }
 
 func (q *Queue[T]) Items() (items []T) {
@@ -140,18 +1283,91 @@ func (q *Queue[T]) Items() (items []T) {
 	return
 }
 
+// Chunks returns an iterator over the queue contents in successive slices of
+// size elements, head first; the last slice may be shorter. Each iteration
+// works on a snapshot taken under the read lock when it starts, so the queue
+// may change meanwhile without affecting the chunks. This call panics if size
+// is not positive.
+func (q *Queue[T]) Chunks(size int) iter.Seq[[]T] {
+	if size <= 0 {
+		panic("queue: Chunks() called with non-positive size")
+	}
+	return func(yield func([]T) bool) {
+		items := q.Items()
+		for len(items) > 0 {
+			n := min(size, len(items))
+			if !yield(items[:n:n]) {
+				return
+			}
+			items = items[n:]
+		}
+	}
+}
+
+// ForEach calls fn for each element from head to tail under the read lock,
+// stopping early if fn returns false. Unlike Items it does not allocate. fn
+// must not call back into the queue, as that would recursively lock it.
+func (q *Queue[T]) ForEach(fn func(T) bool) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	for i := 0; i < q.count; i++ {
+		// bitwise modulus
+		if !fn(q.buf[(q.head+i)&(len(q.buf)-1)]) {
+			return
+		}
+	}
+}
+
+// Snapshot copies the queue contents under a brief read lock and streams them,
+// head first, on the returned channel, which is closed after the last element.
+// The lock is not held while the consumer processes the elements, so later
+// changes to the queue are not reflected. The channel must be drained, or the
+// goroutine feeding it leaks.
+func (q *Queue[T]) Snapshot() <-chan T {
+	items := q.Items()
+	ch := make(chan T)
+	go func() {
+		for _, v := range items {
+			ch <- v
+		}
+		close(ch)
+	}()
+	return ch
+}
+
 // Index get the index of value, starts from zero. Return -1, if not exist.
+// Under concurrency the result is best-effort: a concurrent Pop may shift the
+// element before the caller uses the index. Use IndexAndGet to search and read
+// consistently.
 func (q *Queue[T]) Index(val T) int {
 	q.lock.RLock()
+	idx := q.index(val)
+	q.lock.RUnlock()
+	return idx
+}
+
+// IndexAndGet searches for val and reads the element at the index found under
+// a single lock, so that idx and v are consistent with each other. It returns
+// -1 and false if val is not queued.
+func (q *Queue[T]) IndexAndGet(val T) (idx int, v T, ok bool) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	if idx = q.index(val); idx < 0 {
+		return idx, v, false
+	}
+	p, _ := q.physicalIndex(idx)
+	return idx, q.buf[p], true
+}
+
+// index is Index for callers already holding the lock.
+func (q *Queue[T]) index(val T) int {
 	if q.count <= 0 {
-		q.lock.RUnlock()
 		return -1
 	}
 	idx := 0
 	if q.tail > q.head {
 		for i := q.head; i < q.tail; i++ {
 			if q.buf[i] == val {
-				q.lock.RUnlock()
 				return idx
 			}
 			idx++
@@ -159,19 +1375,163 @@ func (q *Queue[T]) Index(val T) int {
 	} else {
 		for i := q.head; i < len(q.buf); i++ {
 			if q.buf[i] == val {
-				q.lock.RUnlock()
 				return idx
 			}
 			idx++
 		}
 		for i := 0; i < q.tail; i++ {
 			if q.buf[i] == val {
-				q.lock.RUnlock()
 				return idx
 			}
 			idx++
 		}
 	}
-	q.lock.RUnlock()
 	return -1
 }
+
+// PushUnique puts an element on the end of the queue and returns true, unless
+// an equal element is already queued, in which case it returns false. The
+// check is a linear Index scan, so each call is O(n); it is meant for small to
+// moderate queues. On a bounded queue it blocks like Push while full, but a
+// duplicate is rejected at once, and the check is repeated on every wakeup.
+func (q *Queue[T]) PushUnique(elem T) bool {
+	q.lock.Lock()
+	defer q.fireWatermarks()
+	defer q.lock.Unlock()
+	if q.index(elem) >= 0 {
+		return false
+	}
+	if q.full() {
+		defer q.recordWait(q.waitStart(), &q.producerWait)
+		for q.full() {
+			q.notFull.Wait()
+			if q.index(elem) >= 0 {
+				// Hand the wakeup on to another producer, which may push.
+				if !q.full() {
+					q.notFull.Signal()
+				}
+				return false
+			}
+		}
+	}
+	q.push(elem)
+	return true
+}
+
+// Equal reports whether q and other hold the same elements in the same order.
+// Both queues are read-locked in address order so that two goroutines comparing
+// the same pair of queues cannot deadlock.
+func (q *Queue[T]) Equal(other *Queue[T]) bool {
+	if q == other {
+		return true
+	}
+	if q == nil || other == nil {
+		return false
+	}
+	first, second := q, other
+	if uintptr(unsafe.Pointer(first)) > uintptr(unsafe.Pointer(second)) {
+		first, second = second, first
+	}
+	first.lock.RLock()
+	second.lock.RLock()
+	defer first.lock.RUnlock()
+	defer second.lock.RUnlock()
+
+	if q.count != other.count {
+		return false
+	}
+	for i := 0; i < q.count; i++ {
+		// bitwise modulus
+		if q.buf[(q.head+i)&(len(q.buf)-1)] != other.buf[(other.head+i)&(len(other.buf)-1)] {
+			return false
+		}
+	}
+	return true
+}
+
+// IndexFunc returns the index of the first element satisfying pred, starting
+// from zero. Return -1, if none does. Unlike Index it does not rely on ==, so
+// elements may be matched on a key field.
+func IndexFunc[T comparable](q *Queue[T], pred func(T) bool) int {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	for i := 0; i < q.count; i++ {
+		// bitwise modulus
+		if pred(q.buf[(q.head+i)&(len(q.buf)-1)]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Partition returns two new queues holding, in order, the elements of q
+// satisfying pred and the rest. q is only read, under its read lock.
+func Partition[T comparable](q *Queue[T], pred func(T) bool) (match, rest *Queue[T]) {
+	var matched, others []T
+	q.lock.RLock()
+	for i := 0; i < q.count; i++ {
+		// bitwise modulus
+		v := q.buf[(q.head+i)&(len(q.buf)-1)]
+		if pred(v) {
+			matched = append(matched, v)
+		} else {
+			others = append(others, v)
+		}
+	}
+	q.lock.RUnlock()
+	return NewQueueFromSlice(matched), NewQueueFromSlice(others)
+}
+
+// Pair holds one element from each queue passed to Zip.
+type Pair[A, B comparable] struct {
+	First  A
+	Second B
+}
+
+// Zip returns a new queue pairing the elements of qa and qb position by
+// position, up to the length of the shorter one. Each source is read in turn
+// under its own read lock and is left untouched.
+func Zip[A, B comparable](qa *Queue[A], qb *Queue[B]) *Queue[Pair[A, B]] {
+	as, bs := qa.Items(), qb.Items()
+	pairs := make([]Pair[A, B], min(len(as), len(bs)))
+	for i := range pairs {
+		pairs[i] = Pair[A, B]{as[i], bs[i]}
+	}
+	return NewQueueFromSlice(pairs)
+}
+
+// Reduce folds f over the elements from head to tail, starting from init, and
+// returns the result. The queue is read-locked for the duration, so f must not
+// call back into q for writing.
+func Reduce[T comparable, A any](q *Queue[T], init A, f func(A, T) A) A {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	acc := init
+	for i := 0; i < q.count; i++ {
+		// bitwise modulus
+		acc = f(acc, q.buf[(q.head+i)&(len(q.buf)-1)])
+	}
+	return acc
+}
+
+// String renders the queue as [e0 e1 ... eN] from head to tail. Only the first
+// maxStringLen elements are rendered, followed by an ellipsis.
+func (q *Queue[T]) String() string {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i := 0; i < q.count; i++ {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		if i == maxStringLen {
+			sb.WriteString("...")
+			break
+		}
+		// bitwise modulus
+		fmt.Fprint(&sb, q.buf[(q.head+i)&(len(q.buf)-1)])
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
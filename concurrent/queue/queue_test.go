@@ -1,10 +1,19 @@
 package queue
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"math/rand"
 	"runtime"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -93,6 +102,1898 @@ func TestQueue_Index(t *testing.T) {
 	})
 }
 
+func TestQueue_Equal(t *testing.T) {
+	Convey("test Queue Equal", t, func() {
+		Convey("test Queue Equal same contents", func() {
+			a, b := NewQueue[int](), NewQueue[int]()
+			for i := 0; i < minQueueLen; i++ {
+				a.Push(i)
+			}
+			// wrap b around so the physical layouts differ
+			for i := 0; i < 5; i++ {
+				b.Push(-1)
+				b.Pop()
+			}
+			for i := 0; i < minQueueLen; i++ {
+				b.Push(i)
+			}
+			So(a.Equal(b), ShouldBeTrue)
+			So(b.Equal(a), ShouldBeTrue)
+			So(a.Equal(a), ShouldBeTrue)
+		})
+
+		Convey("test Queue Equal different order", func() {
+			a, b := NewQueue[int](), NewQueue[int]()
+			for i := 0; i < 3; i++ {
+				a.Push(i)
+				b.Push(2 - i)
+			}
+			So(a.Equal(b), ShouldBeFalse)
+		})
+
+		Convey("test Queue Equal different length", func() {
+			a, b := NewQueue[int](), NewQueue[int]()
+			for i := 0; i < 3; i++ {
+				a.Push(i)
+				b.Push(i)
+			}
+			b.Push(3)
+			So(a.Equal(b), ShouldBeFalse)
+			So(NewQueue[int]().Equal(NewQueue[int]()), ShouldBeTrue)
+		})
+	})
+}
+
+func TestNewQueueFromSlice(t *testing.T) {
+	Convey("test NewQueueFromSlice", t, func() {
+		Convey("test NewQueueFromSlice pop order", func() {
+			items := []int{5, 3, 8, 1}
+			q := NewQueueFromSlice(items)
+			So(q.Size(), ShouldEqual, len(items))
+			for _, want := range items {
+				v, ok := q.Pop()
+				So(ok, ShouldBeTrue)
+				So(v, ShouldEqual, want)
+			}
+			So(q.Empty(), ShouldBeTrue)
+		})
+
+		Convey("test NewQueueFromSlice longer than minQueueLen", func() {
+			items := make([]int, minQueueLen*2+1)
+			for i := range items {
+				items[i] = i
+			}
+			q := NewQueueFromSlice(items)
+			So(len(q.buf), ShouldEqual, minQueueLen*4)
+			q.Push(len(items))
+			So(q.Items(), ShouldResemble, append(items, len(items)))
+		})
+
+		Convey("test NewQueueFromSlice exactly full", func() {
+			items := make([]int, minQueueLen)
+			for i := range items {
+				items[i] = i
+			}
+			q := NewQueueFromSlice(items)
+			q.Push(minQueueLen)
+			So(q.Size(), ShouldEqual, minQueueLen+1)
+			So(q.Get(-1), ShouldEqual, minQueueLen)
+			So(q.Peek(), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestQueue_Rotate(t *testing.T) {
+	// wrapped returns a queue holding 0..n-1 whose contents wrap around the end of buf.
+	wrapped := func(n int) *Queue[int] {
+		q := NewQueue[int]()
+		for i := 0; i < minQueueLen-3; i++ {
+			q.Push(-1)
+			q.Pop()
+		}
+		for i := 0; i < n; i++ {
+			q.Push(i)
+		}
+		return q
+	}
+
+	Convey("test Queue Rotate", t, func() {
+		Convey("test Queue Rotate forward", func() {
+			q := wrapped(6)
+			q.Rotate(2)
+			So(q.Items(), ShouldResemble, []int{2, 3, 4, 5, 0, 1})
+			q.Rotate(5)
+			So(q.Items(), ShouldResemble, []int{1, 2, 3, 4, 5, 0})
+		})
+
+		Convey("test Queue Rotate backward", func() {
+			q := wrapped(6)
+			q.Rotate(-1)
+			So(q.Items(), ShouldResemble, []int{5, 0, 1, 2, 3, 4})
+			q.Rotate(-4)
+			So(q.Items(), ShouldResemble, []int{1, 2, 3, 4, 5, 0})
+		})
+
+		Convey("test Queue Rotate modulo count", func() {
+			q := wrapped(6)
+			q.Rotate(6)
+			So(q.Items(), ShouldResemble, []int{0, 1, 2, 3, 4, 5})
+			q.Rotate(13)
+			So(q.Items(), ShouldResemble, []int{1, 2, 3, 4, 5, 0})
+			q.Rotate(-13)
+			So(q.Items(), ShouldResemble, []int{0, 1, 2, 3, 4, 5})
+		})
+
+		Convey("test Queue Rotate full buffer", func() {
+			q := wrapped(minQueueLen)
+			q.Rotate(3)
+			So(q.Peek(), ShouldEqual, 3)
+			So(q.Get(-1), ShouldEqual, 2)
+			q.Push(minQueueLen)
+			So(q.Get(-1), ShouldEqual, minQueueLen)
+			So(q.Get(-2), ShouldEqual, 2)
+		})
+	})
+}
+
+func TestQueue_PushWait(t *testing.T) {
+	Convey("test Queue PushWait", t, func() {
+		Convey("test Queue PushWait unblocked by Pop", func() {
+			q := NewBoundedQueue[int](2)
+			So(q.PushWait(context.Background(), 1), ShouldBeNil)
+			So(q.PushWait(context.Background(), 2), ShouldBeNil)
+
+			errc := make(chan error, 1)
+			go func() {
+				errc <- q.PushWait(context.Background(), 3)
+			}()
+			select {
+			case <-errc:
+				t.Fatal("PushWait returned on a full queue")
+			case <-time.After(20 * time.Millisecond):
+			}
+
+			v, ok := q.Pop()
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, 1)
+			So(<-errc, ShouldBeNil)
+			So(q.Items(), ShouldResemble, []int{2, 3})
+		})
+
+		Convey("test Queue PushWait cancelled", func() {
+			q := NewBoundedQueue[int](1)
+			q.Push(1)
+			ctx, cancel := context.WithCancel(context.Background())
+			errc := make(chan error, 1)
+			go func() {
+				errc <- q.PushWait(ctx, 2)
+			}()
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+			So(<-errc, ShouldEqual, context.Canceled)
+			So(q.Items(), ShouldResemble, []int{1})
+		})
+
+		Convey("test Queue PushWait unbounded", func() {
+			q := NewQueue[int]()
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			So(q.PushWait(ctx, 1), ShouldBeNil)
+			So(q.Size(), ShouldEqual, 1)
+		})
+	})
+}
+
+func TestQueue_PushSeq(t *testing.T) {
+	Convey("test Queue PushSeq", t, func() {
+		Convey("test Queue PushSeq from slices.Values", func() {
+			q := NewQueue[int]()
+			q.Push(-1)
+			q.PushSeq(slices.Values([]int{1, 2, 3}))
+			So(q.Size(), ShouldEqual, 4)
+			So(q.Items(), ShouldResemble, []int{-1, 1, 2, 3})
+		})
+
+		Convey("test Queue PushSeq from generator", func() {
+			n := minQueueLen*3 + 1
+			q := NewQueue[int]()
+			q.PushSeq(func(yield func(int) bool) {
+				for i := 0; i < n; i++ {
+					if !yield(i) {
+						return
+					}
+				}
+			})
+			So(q.Size(), ShouldEqual, n)
+			for i := 0; i < n; i++ {
+				v, ok := q.Pop()
+				So(ok, ShouldBeTrue)
+				So(v, ShouldEqual, i)
+			}
+		})
+	})
+}
+
+func TestIndexFunc(t *testing.T) {
+	type job struct {
+		id   int
+		name string
+	}
+
+	Convey("test IndexFunc", t, func() {
+		q := NewQueue[job]()
+		for i := 0; i < minQueueLen; i++ {
+			q.Push(job{id: i})
+		}
+		for i := 0; i < 4; i++ {
+			q.Pop()
+		}
+		q.Push(job{id: 100, name: "wrapped"})
+
+		Convey("test IndexFunc match on field", func() {
+			So(IndexFunc(q, func(j job) bool { return j.id == 5 }), ShouldEqual, 1)
+			So(IndexFunc(q, func(j job) bool { return j.name == "wrapped" }), ShouldEqual, minQueueLen-4)
+		})
+
+		Convey("test IndexFunc absent", func() {
+			So(IndexFunc(q, func(j job) bool { return j.id == 2 }), ShouldEqual, -1)
+			So(IndexFunc(NewQueue[job](), func(job) bool { return true }), ShouldEqual, -1)
+		})
+	})
+}
+
+func TestQueue_SetSwap(t *testing.T) {
+	Convey("test Queue Set and Swap", t, func() {
+		q := NewQueue[int]()
+		for i := 0; i < minQueueLen; i++ {
+			q.Push(i)
+		}
+		for i := 0; i < 10; i++ {
+			q.Pop()
+		}
+		for i := 0; i < 4; i++ {
+			q.Push(100 + i)
+		}
+		// q is now [10 .. 15 100 .. 103], wrapped around the end of buf.
+
+		Convey("test Queue Set", func() {
+			q.Set(0, -10)
+			q.Set(-1, -103)
+			q.Set(6, -100)
+			So(q.Items(), ShouldResemble, []int{-10, 11, 12, 13, 14, 15, -100, 101, 102, -103})
+		})
+
+		Convey("test Queue Swap", func() {
+			q.Swap(0, -1)
+			q.Swap(5, 6)
+			q.Swap(2, 2)
+			So(q.Items(), ShouldResemble, []int{103, 11, 12, 13, 14, 100, 15, 101, 102, 10})
+		})
+
+		Convey("test Queue Set and Swap out of range", func() {
+			So(func() { q.Set(10, 0) }, ShouldPanic)
+			So(func() { q.Set(-11, 0) }, ShouldPanic)
+			So(func() { q.Swap(0, 10) }, ShouldPanic)
+			So(func() { q.Swap(-11, 0) }, ShouldPanic)
+		})
+	})
+}
+
+func TestQueue_String(t *testing.T) {
+	Convey("test Queue String", t, func() {
+		Convey("test Queue String empty", func() {
+			So(NewQueue[int]().String(), ShouldEqual, "[]")
+		})
+
+		Convey("test Queue String non-empty", func() {
+			q := NewQueue[string]()
+			q.Push("a")
+			q.Push("b")
+			q.Push("c")
+			q.Pop()
+			So(q.String(), ShouldEqual, "[b c]")
+		})
+
+		Convey("test Queue String truncated", func() {
+			q := NewQueue[int]()
+			for i := 0; i < maxStringLen+10; i++ {
+				q.Push(0)
+			}
+			So(q.String(), ShouldEqual, "["+strings.Repeat("0 ", maxStringLen)+"...]")
+		})
+	})
+}
+
+func TestQueue_Snapshot(t *testing.T) {
+	Convey("test Queue Snapshot", t, func() {
+		q := NewQueue[int]()
+		for i := 0; i < minQueueLen; i++ {
+			q.Push(i)
+		}
+		for i := 0; i < 5; i++ {
+			q.Pop()
+		}
+		q.Push(100)
+		want := q.Items()
+
+		ch := q.Snapshot()
+		// Mutations after the snapshot must not show up in it.
+		q.Push(200)
+		q.Pop()
+
+		var got []int
+		for v := range ch {
+			got = append(got, v)
+		}
+		So(got, ShouldResemble, want)
+
+		var empty []int
+		for v := range NewQueue[int]().Snapshot() {
+			empty = append(empty, v)
+		}
+		So(empty, ShouldBeEmpty)
+	})
+}
+
+func TestQueue_SetShrinkPolicy(t *testing.T) {
+	// oscillate fills q to twice minQueueLen, then repeatedly crosses the
+	// quarter-full boundary, returning the buffer sizes seen after each pop.
+	oscillate := func(q *Queue[int]) (sizes []int) {
+		for i := 0; i < minQueueLen*2; i++ {
+			q.Push(i)
+		}
+		for i := 0; i < minQueueLen*2-minQueueLen/2+1; i++ {
+			q.Pop()
+		}
+		for i := 0; i < 10; i++ {
+			q.Pop()
+			sizes = append(sizes, len(q.buf))
+			q.Push(i)
+			q.Push(i)
+			q.Pop()
+		}
+		return
+	}
+
+	Convey("test Queue SetShrinkPolicy", t, func() {
+		Convey("test Queue shrinks by default", func() {
+			q := NewQueue[int]()
+			for i := 0; i < minQueueLen*2; i++ {
+				q.Push(i)
+			}
+			for q.Size() > minQueueLen/2 {
+				q.Pop()
+			}
+			So(len(q.buf), ShouldEqual, minQueueLen)
+		})
+
+		Convey("test Queue shrink disabled", func() {
+			q := NewQueue[int]()
+			q.SetShrinkPolicy(false)
+			for _, size := range oscillate(q) {
+				So(size, ShouldEqual, minQueueLen*2)
+			}
+			for !q.Empty() {
+				q.Pop()
+			}
+			So(len(q.buf), ShouldEqual, minQueueLen*2)
+		})
+	})
+}
+
+func TestQueue_PopIf(t *testing.T) {
+	Convey("test Queue PopIf", t, func() {
+		q := NewQueue[string]()
+		q.Push("a")
+		q.Push("b")
+
+		Convey("test Queue PopIf match", func() {
+			v, ok := q.PopIf("a")
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, "a")
+			So(q.Items(), ShouldResemble, []string{"b"})
+		})
+
+		Convey("test Queue PopIf mismatch", func() {
+			v, ok := q.PopIf("b")
+			So(ok, ShouldBeFalse)
+			So(v, ShouldEqual, "")
+			So(q.Items(), ShouldResemble, []string{"a", "b"})
+		})
+
+		Convey("test Queue PopIf empty", func() {
+			_, ok := NewQueue[string]().PopIf("")
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
+
+func TestQueue_PeekN(t *testing.T) {
+	Convey("test Queue PeekN", t, func() {
+		q := NewQueue[int]()
+		for i := 0; i < minQueueLen; i++ {
+			q.Push(i)
+		}
+		for i := 0; i < minQueueLen-2; i++ {
+			q.Pop()
+		}
+		q.Push(100)
+		q.Push(101)
+		// q is now [14 15 100 101], wrapped around the end of buf.
+
+		Convey("test Queue PeekN wrapped", func() {
+			So(q.PeekN(3), ShouldResemble, []int{14, 15, 100})
+			So(q.Size(), ShouldEqual, 4)
+		})
+
+		Convey("test Queue PeekN larger than size", func() {
+			So(q.PeekN(10), ShouldResemble, []int{14, 15, 100, 101})
+		})
+
+		Convey("test Queue PeekN zero", func() {
+			So(q.PeekN(0), ShouldBeEmpty)
+			So(NewQueue[int]().PeekN(3), ShouldBeEmpty)
+		})
+
+		Convey("test Queue PeekN does not alias", func() {
+			items := q.PeekN(2)
+			items[0] = -1
+			So(q.Peek(), ShouldEqual, 14)
+		})
+	})
+}
+
+func TestQueue_IndexRange(t *testing.T) {
+	Convey("test Queue index based methods", t, func() {
+		q := NewQueue[int]()
+		for i := 0; i < minQueueLen; i++ {
+			q.Push(i)
+		}
+		for i := 0; i < minQueueLen-2; i++ {
+			q.Pop()
+		}
+		q.Push(100)
+		// q is now [14 15 100], wrapped around the end of buf.
+
+		Convey("test Queue negative indices", func() {
+			So(q.Get(-1), ShouldEqual, 100)
+			So(q.Get(-3), ShouldEqual, 14)
+			q.Set(-2, -15)
+			So(q.Get(1), ShouldEqual, -15)
+			q.Swap(-1, -3)
+			So(q.Items(), ShouldResemble, []int{100, -15, 14})
+		})
+
+		Convey("test Queue out of range indices", func() {
+			for _, i := range []int{3, 4, -4, -100} {
+				So(func() { q.Get(i) }, ShouldPanicWith, "queue: Get() called with index out of range")
+				So(func() { q.Set(i, 0) }, ShouldPanicWith, "queue: Set() called with index out of range")
+				So(func() { q.Swap(i, 0) }, ShouldPanicWith, "queue: Swap() called with index out of range")
+				So(func() { q.Swap(0, i) }, ShouldPanicWith, "queue: Swap() called with index out of range")
+			}
+			So(q.Items(), ShouldResemble, []int{14, 15, 100})
+			So(func() { NewQueue[int]().Get(0) }, ShouldPanic)
+		})
+	})
+}
+
+func TestQueue_Move(t *testing.T) {
+	Convey("test Queue MoveToFront and MoveToBack", t, func() {
+		q := NewQueue[int]()
+		for i := 0; i < minQueueLen; i++ {
+			q.Push(i)
+		}
+		for i := 0; i < minQueueLen-3; i++ {
+			q.Pop()
+		}
+		for i := 0; i < 3; i++ {
+			q.Push(100 + i)
+		}
+		// q is now [13 14 15 100 101 102], wrapped around the end of buf.
+
+		Convey("test Queue MoveToFront", func() {
+			q.MoveToFront(4)
+			So(q.Items(), ShouldResemble, []int{101, 13, 14, 15, 100, 102})
+			q.MoveToFront(-1)
+			So(q.Items(), ShouldResemble, []int{102, 101, 13, 14, 15, 100})
+			q.MoveToFront(0)
+			So(q.Items(), ShouldResemble, []int{102, 101, 13, 14, 15, 100})
+		})
+
+		Convey("test Queue MoveToBack", func() {
+			q.MoveToBack(1)
+			So(q.Items(), ShouldResemble, []int{13, 15, 100, 101, 102, 14})
+			q.MoveToBack(-6)
+			So(q.Items(), ShouldResemble, []int{15, 100, 101, 102, 14, 13})
+			q.MoveToBack(-1)
+			So(q.Items(), ShouldResemble, []int{15, 100, 101, 102, 14, 13})
+			q.Push(200)
+			So(q.Get(-1), ShouldEqual, 200)
+		})
+
+		Convey("test Queue Move out of range", func() {
+			So(func() { q.MoveToFront(6) }, ShouldPanic)
+			So(func() { q.MoveToBack(-7) }, ShouldPanic)
+		})
+	})
+}
+
+func TestQueue_ForEach(t *testing.T) {
+	Convey("test Queue ForEach", t, func() {
+		q := NewQueue[int]()
+		for i := 0; i < minQueueLen; i++ {
+			q.Push(i)
+		}
+		for i := 0; i < 10; i++ {
+			q.Pop()
+		}
+		for i := minQueueLen; i < minQueueLen+4; i++ {
+			q.Push(i)
+		}
+		// q is now [10 .. 19], wrapped around the end of buf.
+
+		Convey("test Queue ForEach sum", func() {
+			sum := 0
+			q.ForEach(func(v int) bool {
+				sum += v
+				return true
+			})
+			So(sum, ShouldEqual, 145)
+		})
+
+		Convey("test Queue ForEach stops early", func() {
+			var seen []int
+			q.ForEach(func(v int) bool {
+				seen = append(seen, v)
+				return v < 12
+			})
+			So(seen, ShouldResemble, []int{10, 11, 12})
+		})
+	})
+}
+
+func TestQueue_FrontBack(t *testing.T) {
+	Convey("test Queue Front and Back", t, func() {
+		Convey("test Queue Front and Back empty", func() {
+			q := NewQueue[int]()
+			_, ok := q.Front()
+			So(ok, ShouldBeFalse)
+			_, ok = q.Back()
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("test Queue Front and Back single element", func() {
+			q := NewQueue[int]()
+			q.Push(7)
+			f, ok := q.Front()
+			So(ok, ShouldBeTrue)
+			So(f, ShouldEqual, 7)
+			b, ok := q.Back()
+			So(ok, ShouldBeTrue)
+			So(b, ShouldEqual, 7)
+		})
+
+		Convey("test Queue Front and Back multiple elements", func() {
+			q := NewQueue[int]()
+			for i := 0; i < minQueueLen; i++ {
+				q.Push(i)
+			}
+			// tail is 0 here, so Back must wrap to the end of buf.
+			b, _ := q.Back()
+			So(b, ShouldEqual, minQueueLen-1)
+			q.Pop()
+			q.Push(100)
+			f, _ := q.Front()
+			So(f, ShouldEqual, 1)
+			b, _ = q.Back()
+			So(b, ShouldEqual, 100)
+			So(q.Size(), ShouldEqual, minQueueLen)
+		})
+	})
+}
+
+func TestQueue_CopyTo(t *testing.T) {
+	Convey("test Queue CopyTo", t, func() {
+		q := NewQueue[int]()
+		for i := 0; i < minQueueLen; i++ {
+			q.Push(i)
+		}
+		for i := 0; i < minQueueLen-2; i++ {
+			q.Pop()
+		}
+		q.Push(100)
+		q.Push(101)
+		// q is now [14 15 100 101], wrapped around the end of buf.
+
+		Convey("test Queue CopyTo smaller dst", func() {
+			dst := make([]int, 3)
+			So(q.CopyTo(dst), ShouldEqual, 3)
+			So(dst, ShouldResemble, []int{14, 15, 100})
+		})
+
+		Convey("test Queue CopyTo equal dst", func() {
+			dst := make([]int, 4)
+			So(q.CopyTo(dst), ShouldEqual, 4)
+			So(dst, ShouldResemble, []int{14, 15, 100, 101})
+		})
+
+		Convey("test Queue CopyTo larger dst", func() {
+			dst := []int{-1, -1, -1, -1, -1, -1}
+			So(q.CopyTo(dst), ShouldEqual, 4)
+			So(dst, ShouldResemble, []int{14, 15, 100, 101, -1, -1})
+			So(NewQueue[int]().CopyTo(dst), ShouldEqual, 0)
+		})
+	})
+}
+
+func BenchmarkQueue_CopyTo(b *testing.B) {
+	q := NewQueue[int]()
+	for i := 0; i < 1000; i++ {
+		q.Push(i)
+	}
+	dst := make([]int, q.Size())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.CopyTo(dst)
+	}
+}
+
+func TestQueue_Dedup(t *testing.T) {
+	Convey("test Queue Dedup", t, func() {
+		Convey("test Queue Dedup no duplicates", func() {
+			q := NewQueueFromSlice([]int{1, 2, 1, 2})
+			So(q.Dedup(), ShouldEqual, 0)
+			So(q.Items(), ShouldResemble, []int{1, 2, 1, 2})
+		})
+
+		Convey("test Queue Dedup all same", func() {
+			q := NewQueueFromSlice([]int{7, 7, 7, 7, 7})
+			So(q.Dedup(), ShouldEqual, 4)
+			So(q.Items(), ShouldResemble, []int{7})
+			q.Push(8)
+			So(q.Items(), ShouldResemble, []int{7, 8})
+		})
+
+		Convey("test Queue Dedup interleaved", func() {
+			q := NewQueue[int]()
+			for i := 0; i < minQueueLen-4; i++ {
+				q.Push(-1)
+				q.Pop()
+			}
+			for _, v := range []int{1, 1, 2, 3, 3, 3, 1, 4, 4} {
+				q.Push(v)
+			}
+			So(q.Dedup(), ShouldEqual, 4)
+			So(q.Items(), ShouldResemble, []int{1, 2, 3, 1, 4})
+			So(q.Size(), ShouldEqual, 5)
+		})
+	})
+}
+
+func TestQueue_PushUnique(t *testing.T) {
+	Convey("test Queue PushUnique", t, func() {
+		q := NewQueue[string]()
+		So(q.PushUnique("a"), ShouldBeTrue)
+		So(q.PushUnique("b"), ShouldBeTrue)
+		So(q.PushUnique("a"), ShouldBeFalse)
+		So(q.PushUnique("b"), ShouldBeFalse)
+		So(q.Items(), ShouldResemble, []string{"a", "b"})
+
+		// Once popped, a value may be queued again.
+		q.Pop()
+		So(q.PushUnique("a"), ShouldBeTrue)
+		So(q.Items(), ShouldResemble, []string{"b", "a"})
+
+		Convey("test Queue PushUnique bounded", func() {
+			q := NewBoundedQueue[string](2)
+			So(q.PushUnique("a"), ShouldBeTrue)
+			So(q.PushUnique("b"), ShouldBeTrue)
+
+			// A duplicate is rejected without waiting for a free slot.
+			done := make(chan bool, 1)
+			go func() { done <- q.PushUnique("a") }()
+			select {
+			case ok := <-done:
+				So(ok, ShouldBeFalse)
+			case <-time.After(time.Second):
+				So("PushUnique blocked on a duplicate", ShouldBeEmpty)
+			}
+
+			// An equal element queued while waiting is caught after wakeup.
+			go func() { done <- q.PushUnique("c") }()
+			time.Sleep(10 * time.Millisecond)
+			q.lock.Lock()
+			q.pop()
+			q.push("c")
+			q.pop()
+			q.lock.Unlock()
+			So(<-done, ShouldBeFalse)
+			So(q.Items(), ShouldResemble, []string{"c"})
+		})
+	})
+}
+
+func TestQueue_SetAdaptive(t *testing.T) {
+	// spikes pushes and drains 1000 elements a few times, returning how often
+	// the buffer was reallocated.
+	spikes := func(q *Queue[int]) (resizes int) {
+		size := len(q.buf)
+		observe := func() {
+			if len(q.buf) != size {
+				size = len(q.buf)
+				resizes++
+			}
+		}
+		for round := 0; round < 5; round++ {
+			for i := 0; i < 1000; i++ {
+				q.Push(i)
+				observe()
+			}
+			for i := 0; i < 1000; i++ {
+				q.Pop()
+				observe()
+			}
+		}
+		return
+	}
+
+	Convey("test Queue SetAdaptive", t, func() {
+		plain := NewQueue[int]()
+		adaptive := NewQueue[int]()
+		adaptive.SetAdaptive(true, 2000)
+
+		plainResizes := spikes(plain)
+		adaptiveResizes := spikes(adaptive)
+		So(adaptiveResizes, ShouldBeLessThan, plainResizes)
+		// Only the first spike grows the buffer; it is never shrunk.
+		So(adaptiveResizes, ShouldEqual, 6)
+
+		Convey("test Queue SetAdaptive peak expires", func() {
+			for i := 0; i < 4000; i++ {
+				adaptive.Push(i)
+				adaptive.Pop()
+			}
+			// The old peak of 1000 is forgotten, so the buffer shrinks
+			// again, but not below what the new peak of 300 needs.
+			for i := 0; i < 300; i++ {
+				adaptive.Push(i)
+			}
+			for !adaptive.Empty() {
+				adaptive.Pop()
+			}
+			So(len(adaptive.buf), ShouldEqual, 512)
+		})
+	})
+}
+
+func TestQueue_WithBatch(t *testing.T) {
+	Convey("test Queue WithBatch", t, func() {
+		q := NewQueueFromSlice([]int{1, 2, 3, 4, 5})
+
+		Convey("test Queue WithBatch success", func() {
+			var got []int
+			err := q.WithBatch(3, func(batch []int) error {
+				got = append(got, batch...)
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(got, ShouldResemble, []int{1, 2, 3})
+			So(q.Items(), ShouldResemble, []int{4, 5})
+		})
+
+		Convey("test Queue WithBatch rollback", func() {
+			errFailed := errors.New("failed")
+			err := q.WithBatch(3, func(batch []int) error {
+				So(batch, ShouldResemble, []int{1, 2, 3})
+				q.Push(6)
+				return errFailed
+			})
+			So(err, ShouldEqual, errFailed)
+			So(q.Items(), ShouldResemble, []int{1, 2, 3, 4, 5, 6})
+		})
+
+		Convey("test Queue WithBatch max larger than size", func() {
+			err := q.WithBatch(10, func(batch []int) error {
+				So(batch, ShouldResemble, []int{1, 2, 3, 4, 5})
+				return errors.New("failed")
+			})
+			So(err, ShouldNotBeNil)
+			So(q.Items(), ShouldResemble, []int{1, 2, 3, 4, 5})
+		})
+	})
+}
+
+func TestQueue_PeekTailN(t *testing.T) {
+	Convey("test Queue PeekTailN", t, func() {
+		q := NewQueue[int]()
+		for i := 0; i < minQueueLen; i++ {
+			q.Push(i)
+		}
+		for i := 0; i < minQueueLen-2; i++ {
+			q.Pop()
+		}
+		q.Push(100)
+		q.Push(101)
+		// q is now [14 15 100 101], wrapped around the end of buf.
+
+		Convey("test Queue PeekTailN within the wrapped part", func() {
+			So(q.PeekTailN(2), ShouldResemble, []int{100, 101})
+		})
+
+		Convey("test Queue PeekTailN across the wrap", func() {
+			So(q.PeekTailN(3), ShouldResemble, []int{15, 100, 101})
+			So(q.Size(), ShouldEqual, 4)
+		})
+
+		Convey("test Queue PeekTailN larger than size", func() {
+			So(q.PeekTailN(10), ShouldResemble, []int{14, 15, 100, 101})
+		})
+
+		Convey("test Queue PeekTailN zero and empty", func() {
+			So(q.PeekTailN(0), ShouldBeEmpty)
+			So(q.PeekTailN(-1), ShouldBeEmpty)
+			So(NewQueue[int]().PeekTailN(3), ShouldBeEmpty)
+		})
+
+		Convey("test Queue PeekTailN full buffer", func() {
+			full := NewQueue[int]()
+			for i := 0; i < minQueueLen; i++ {
+				full.Push(i)
+			}
+			So(full.PeekTailN(2), ShouldResemble, []int{minQueueLen - 2, minQueueLen - 1})
+			So(len(full.PeekTailN(minQueueLen)), ShouldEqual, minQueueLen)
+		})
+	})
+}
+
+func TestQueue_PopWait(t *testing.T) {
+	Convey("test Queue PopWait", t, func() {
+		Convey("test Queue PopWait unblocked by Push", func() {
+			q := NewQueue[int]()
+			vc := make(chan int, 1)
+			go func() {
+				v, _ := q.PopWait(context.Background())
+				vc <- v
+			}()
+			time.Sleep(10 * time.Millisecond)
+			q.Push(7)
+			So(<-vc, ShouldEqual, 7)
+		})
+
+		Convey("test Queue PopWait cancelled", func() {
+			q := NewQueue[int]()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			_, err := q.PopWait(ctx)
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		})
+
+		Convey("test Queue PopWait closed", func() {
+			q := NewQueue[int]()
+			q.Push(1)
+			errc := make(chan error, 1)
+			go func() {
+				q.PopWait(context.Background())
+				// Blocks on the drained queue until Close.
+				_, err := q.PopWait(context.Background())
+				errc <- err
+			}()
+			time.Sleep(10 * time.Millisecond)
+			q.Close()
+			select {
+			case err := <-errc:
+				So(err, ShouldEqual, ErrClosed)
+			case <-time.After(time.Second):
+				So("PopWait still blocked after Close", ShouldBeEmpty)
+			}
+			_, err := q.PopWait(context.Background())
+			So(err, ShouldEqual, ErrClosed)
+
+			// Channel drains a closed queue, then closes.
+			q = NewQueueFromSlice([]int{1, 2})
+			q.Close()
+			var got []int
+			for v := range q.Channel(context.Background(), 0) {
+				got = append(got, v)
+			}
+			So(got, ShouldResemble, []int{1, 2})
+		})
+	})
+}
+
+func TestQueue_Channel(t *testing.T) {
+	Convey("test Queue Channel", t, func() {
+		q := NewQueueFromSlice([]int{1, 2, 3})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := q.Channel(ctx, 0)
+
+		var got []int
+		for v := range ch {
+			got = append(got, v)
+			if len(got) == 3 {
+				q.Push(4)
+			}
+			if len(got) == 4 {
+				q.Push(5)
+				cancel()
+			}
+		}
+		So(got[:4], ShouldResemble, []int{1, 2, 3, 4})
+		// 5 was either delivered before the cancellation was noticed, or
+		// left in the queue.
+		So(len(got)+q.Size(), ShouldEqual, 5)
+	})
+}
+
+func TestQueue_FromChannel(t *testing.T) {
+	Convey("test Queue FromChannel", t, func() {
+		Convey("test Queue FromChannel closed source", func() {
+			src := make(chan int, 5)
+			for i := 0; i < 5; i++ {
+				src <- i
+			}
+			close(src)
+			q := NewQueue[int]()
+			<-q.FromChannel(context.Background(), src)
+			So(q.Items(), ShouldResemble, []int{0, 1, 2, 3, 4})
+		})
+
+		Convey("test Queue FromChannel cancelled", func() {
+			src := make(chan int)
+			q := NewQueue[int]()
+			ctx, cancel := context.WithCancel(context.Background())
+			done := q.FromChannel(ctx, src)
+			src <- 1
+			cancel()
+			<-done
+			So(q.Items(), ShouldResemble, []int{1})
+		})
+
+		Convey("test Queue FromChannel cancelled while full", func() {
+			src := make(chan int, 1)
+			q := NewBoundedQueue[int](1)
+			q.Push(0)
+			ctx, cancel := context.WithCancel(context.Background())
+			done := q.FromChannel(ctx, src)
+			src <- 1
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+			<-done
+			So(q.Items(), ShouldResemble, []int{0})
+		})
+	})
+}
+
+func TestQueue_IndexAndGet(t *testing.T) {
+	Convey("test Queue IndexAndGet", t, func() {
+		Convey("test Queue IndexAndGet absent", func() {
+			idx, _, ok := NewQueueFromSlice([]int{1, 2}).IndexAndGet(3)
+			So(ok, ShouldBeFalse)
+			So(idx, ShouldEqual, -1)
+		})
+
+		Convey("test Queue IndexAndGet under interleaved pops", func() {
+			q := NewQueue[int]()
+			for i := 0; i < 1000; i++ {
+				q.Push(i)
+			}
+			stop := make(chan struct{})
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 1000; ; i++ {
+					select {
+					case <-stop:
+						return
+					default:
+						q.Pop()
+						q.Push(i)
+					}
+				}
+			}()
+			inconsistent := 0
+			for i := 0; i < 2000; i++ {
+				want := 500 + i
+				if idx, v, ok := q.IndexAndGet(want); ok && (v != want || idx < 0 || idx >= 1000) {
+					inconsistent++
+				}
+			}
+			close(stop)
+			<-done
+			So(inconsistent, ShouldEqual, 0)
+		})
+	})
+}
+
+func TestQueue_WaitStats(t *testing.T) {
+	Convey("test Queue WaitStats", t, func() {
+		Convey("test Queue WaitStats disabled", func() {
+			q := NewBoundedQueue[int](1)
+			q.Push(1)
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				q.Pop()
+			}()
+			q.Push(2)
+			p, c, n := q.WaitStats()
+			So(p, ShouldEqual, 0)
+			So(c, ShouldEqual, 0)
+			So(n, ShouldEqual, 0)
+		})
+
+		Convey("test Queue WaitStats enabled", func() {
+			q := NewBoundedQueue[int](1)
+			q.SetWaitStats(true)
+			q.Push(1)
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				q.Pop()
+			}()
+			// Producer waits for the pop above.
+			q.Push(2)
+			q.Pop()
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				q.Push(3)
+			}()
+			// Consumer waits for the push above.
+			v, err := q.PopWait(context.Background())
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, 3)
+
+			p, c, n := q.WaitStats()
+			So(p, ShouldBeGreaterThan, 0)
+			So(c, ShouldBeGreaterThan, 0)
+			So(n, ShouldEqual, 2)
+		})
+	})
+}
+
+func TestQueue_PopN(t *testing.T) {
+	Convey("test Queue PopN", t, func() {
+		Convey("test Queue PopN order", func() {
+			q := NewQueueFromSlice([]int{1, 2, 3, 4, 5})
+			So(q.PopN(2), ShouldResemble, []int{1, 2})
+			So(q.PopN(0), ShouldBeEmpty)
+			So(q.PopN(10), ShouldResemble, []int{3, 4, 5})
+			So(q.PopN(1), ShouldBeEmpty)
+		})
+
+		Convey("test Queue shrinks after batches skip the boundary", func() {
+			q := NewQueue[int]()
+			for i := 0; i < 10000; i++ {
+				q.Push(i)
+			}
+			So(len(q.buf), ShouldEqual, 16384)
+			var resizes [][2]int
+			q.SetOnResize(func(oldCap, newCap int) {
+				resizes = append(resizes, [2]int{oldCap, newCap})
+			})
+			// One batch jumps from above half full to well below a quarter,
+			// never landing on count == len(buf)/4.
+			So(len(q.PopN(9997)), ShouldEqual, 9997)
+			So(resizes, ShouldResemble, [][2]int{{16384, minQueueLen}})
+			So(q.Items(), ShouldResemble, []int{9997, 9998, 9999})
+			checkInvariants(q)
+		})
+
+		Convey("test Queue PopN across the wrap", func() {
+			q := NewQueue[int]()
+			for i := 0; i < 12; i++ {
+				q.Push(-1)
+				q.Pop()
+			}
+			for i := 0; i < 10; i++ {
+				q.Push(i)
+			}
+			So(q.PopN(7), ShouldResemble, []int{0, 1, 2, 3, 4, 5, 6})
+			So(q.Items(), ShouldResemble, []int{7, 8, 9})
+			checkInvariants(q)
+		})
+
+		Convey("test Queue shrinks after Dedup skips the boundary", func() {
+			q := NewQueue[int]()
+			for i := 0; i < 64; i++ {
+				q.Push(i / 60)
+			}
+			q.Dedup()
+			So(len(q.buf), ShouldEqual, 64)
+			q.Pop()
+			So(len(q.buf), ShouldEqual, minQueueLen)
+			So(q.Items(), ShouldResemble, []int{1})
+		})
+	})
+}
+
+func TestPartition(t *testing.T) {
+	Convey("test Partition", t, func() {
+		q := NewQueue[int]()
+		for i := 0; i < 30; i++ {
+			q.Push(i)
+		}
+		for i := 0; i < 10; i++ {
+			q.Pop()
+		}
+		before := q.Items()
+		even, odd := Partition(q, func(v int) bool { return v%2 == 0 })
+		So(even.Items(), ShouldResemble, []int{10, 12, 14, 16, 18, 20, 22, 24, 26, 28})
+		So(odd.Items(), ShouldResemble, []int{11, 13, 15, 17, 19, 21, 23, 25, 27, 29})
+		So(q.Items(), ShouldResemble, before)
+
+		Convey("test Partition empty side", func() {
+			all, none := Partition(q, func(int) bool { return true })
+			So(all.Size(), ShouldEqual, 20)
+			So(none.Empty(), ShouldBeTrue)
+			none.Push(1)
+			So(none.Items(), ShouldResemble, []int{1})
+		})
+	})
+}
+
+func TestZip(t *testing.T) {
+	Convey("test Zip", t, func() {
+		qa := NewQueueFromSlice([]int{1, 2, 3, 4})
+		qb := NewQueueFromSlice([]string{"a", "b", "c"})
+		z := Zip(qa, qb)
+		So(z.Size(), ShouldEqual, 3)
+		So(z.Items(), ShouldResemble, []Pair[int, string]{{1, "a"}, {2, "b"}, {3, "c"}})
+		So(qa.Items(), ShouldResemble, []int{1, 2, 3, 4})
+		So(qb.Items(), ShouldResemble, []string{"a", "b", "c"})
+
+		Convey("test Zip with itself and empty", func() {
+			So(Zip(qa, qa).Get(-1), ShouldResemble, Pair[int, int]{4, 4})
+			So(Zip(qa, NewQueue[int]()).Empty(), ShouldBeTrue)
+		})
+	})
+}
+
+func TestReduce(t *testing.T) {
+	Convey("test Reduce", t, func() {
+		Convey("test Reduce sum", func() {
+			q := NewQueue[int]()
+			So(Reduce(q, 0, func(acc, v int) int { return acc + v }), ShouldEqual, 0)
+			for i := 1; i <= 20; i++ {
+				q.Push(i)
+			}
+			q.Pop()
+			So(Reduce(q, 0, func(acc, v int) int { return acc + v }), ShouldEqual, 209)
+			So(q.Size(), ShouldEqual, 19)
+		})
+
+		Convey("test Reduce concatenation", func() {
+			q := NewQueueFromSlice([]string{"a", "b", "c"})
+			So(Reduce(q, ">", func(acc string, v string) string { return acc + v }), ShouldEqual, ">abc")
+		})
+	})
+}
+
+func TestQueue_Poll(t *testing.T) {
+	Convey("test Queue Poll", t, func() {
+		Convey("test Queue Poll success", func() {
+			q := NewBoundedQueue[int](4)
+			q.Push(1)
+			v, err := q.Poll(time.Second)
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, 1)
+
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				q.Push(2)
+			}()
+			v, err = q.Poll(time.Second)
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, 2)
+		})
+
+		Convey("test Queue Poll timeout", func() {
+			q := NewBoundedQueue[int](4)
+			start := time.Now()
+			v, err := q.Poll(20 * time.Millisecond)
+			So(err, ShouldEqual, ErrTimeout)
+			So(v, ShouldEqual, 0)
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 20*time.Millisecond)
+
+			_, err = q.Poll(0)
+			So(err, ShouldEqual, ErrTimeout)
+		})
+
+		Convey("test Queue Poll closed", func() {
+			q := NewBoundedQueue[int](4)
+			q.Push(1)
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				q.Close()
+			}()
+			v, err := q.Poll(time.Second)
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, 1)
+
+			start := time.Now()
+			_, err = q.Poll(time.Second)
+			So(err, ShouldEqual, ErrClosed)
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+
+			_, err = q.Poll(0)
+			So(err, ShouldEqual, ErrClosed)
+		})
+	})
+}
+
+func TestQueue_Compact(t *testing.T) {
+	Convey("test Queue Compact", t, func() {
+		q := NewQueue[int]()
+		for i := 0; i < 10; i++ {
+			q.Push(i)
+		}
+		for i := 10; i < 20; i++ {
+			q.Pop()
+			q.Push(i)
+		}
+		So(q.tail, ShouldBeLessThan, q.head)
+		items := q.Items()
+		q.Compact()
+		So(q.head, ShouldEqual, 0)
+		So(q.tail, ShouldEqual, 10)
+		So(len(q.buf), ShouldEqual, minQueueLen)
+		So(q.Items(), ShouldResemble, items)
+		checkInvariants(q)
+		q.Push(20)
+		So(q.Get(-1), ShouldEqual, 20)
+
+		Convey("test Queue Compact full", func() {
+			q := NewQueue[int]()
+			for i := 0; i < minQueueLen; i++ {
+				q.Push(i)
+			}
+			q.Pop()
+			q.Push(minQueueLen)
+			q.Compact()
+			So(q.head, ShouldEqual, 0)
+			So(q.tail, ShouldEqual, 0)
+			So(q.Get(0), ShouldEqual, 1)
+			So(q.Get(-1), ShouldEqual, minQueueLen)
+			checkInvariants(q)
+		})
+
+		Convey("test Queue Compact contiguous", func() {
+			q := NewQueueFromSlice([]int{1, 2, 3})
+			q.Compact()
+			So(q.Items(), ShouldResemble, []int{1, 2, 3})
+		})
+	})
+}
+
+func TestQueue_Reverse(t *testing.T) {
+	Convey("test Queue Reverse", t, func() {
+		q := NewQueue[int]()
+		q.Reverse()
+		So(q.Empty(), ShouldBeTrue)
+
+		// Wrap the ring so the contents straddle the buffer boundary.
+		for i := 0; i < 12; i++ {
+			q.Push(-1)
+		}
+		for i := 0; i < 12; i++ {
+			q.Pop()
+			q.Push(i)
+		}
+		q.Push(12)
+		So(q.head+q.count, ShouldBeGreaterThan, len(q.buf))
+		q.Reverse()
+		So(q.Items(), ShouldResemble, []int{12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0})
+		So(q.Get(-1), ShouldEqual, 0)
+		q.Push(-2)
+		So(q.Get(-1), ShouldEqual, -2)
+
+		Convey("test Queue Reverse single", func() {
+			q := NewQueueFromSlice([]int{1})
+			q.Reverse()
+			So(q.Items(), ShouldResemble, []int{1})
+		})
+	})
+}
+
+func TestQueue_Shuffle(t *testing.T) {
+	Convey("test Queue Shuffle", t, func() {
+		q := NewQueue[int]()
+		// Wrap the ring so the shuffle crosses the buffer boundary.
+		for i := 0; i < 10; i++ {
+			q.Push(-1)
+		}
+		for i := 0; i < 10; i++ {
+			q.Push(i)
+			q.Pop()
+		}
+		q.Shuffle(rand.New(rand.NewSource(1)))
+		items := q.Items()
+		So(items, ShouldResemble, []int{4, 8, 2, 5, 3, 9, 0, 7, 6, 1})
+
+		sort.Ints(items)
+		So(items, ShouldResemble, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+
+		Convey("test Queue Shuffle is reproducible", func() {
+			again := NewQueueFromSlice([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+			again.Shuffle(rand.New(rand.NewSource(1)))
+			So(again.Items(), ShouldResemble, []int{4, 8, 2, 5, 3, 9, 0, 7, 6, 1})
+		})
+	})
+}
+
+func TestQueue_HighWaterMark(t *testing.T) {
+	Convey("test Queue HighWaterMark", t, func() {
+		q := NewQueue[int]()
+		So(q.HighWaterMark(), ShouldEqual, 0)
+		for i := 0; i < 100; i++ {
+			q.Push(i)
+		}
+		for i := 0; i < 90; i++ {
+			q.Pop()
+		}
+		q.Push(100)
+		So(q.HighWaterMark(), ShouldEqual, 100)
+
+		q.ResetHighWaterMark()
+		So(q.HighWaterMark(), ShouldEqual, 11)
+		q.Pop()
+		So(q.HighWaterMark(), ShouldEqual, 11)
+		q.Push(101)
+		q.Push(102)
+		So(q.HighWaterMark(), ShouldEqual, 12)
+
+		Convey("test Queue HighWaterMark from slice", func() {
+			So(NewQueueFromSlice([]int{1, 2, 3}).HighWaterMark(), ShouldEqual, 3)
+		})
+	})
+}
+
+func TestQueue_ExtremeIndex(t *testing.T) {
+	Convey("test Queue extreme indices", t, func() {
+		q := NewQueue[int]()
+		// Wrap the ring so head is not at slot zero.
+		for i := 0; i < 20; i++ {
+			q.Push(i)
+		}
+		for i := 0; i < 10; i++ {
+			q.Pop()
+		}
+		for _, i := range []int{math.MaxInt, math.MaxInt - 5, math.MinInt, math.MinInt + 5, 10, -11} {
+			So(func() { q.Get(i) }, ShouldPanicWith, "queue: Get() called with index out of range")
+			So(func() { q.Set(i, 0) }, ShouldPanicWith, "queue: Set() called with index out of range")
+			So(func() { q.Swap(0, i) }, ShouldPanicWith, "queue: Swap() called with index out of range")
+			So(func() { q.MoveToFront(i) }, ShouldPanicWith, "queue: MoveToFront() called with index out of range")
+			So(func() { q.MoveToBack(i) }, ShouldPanicWith, "queue: MoveToBack() called with index out of range")
+		}
+		So(q.Get(9), ShouldEqual, 19)
+		So(q.Get(-10), ShouldEqual, 10)
+		So(q.Items(), ShouldResemble, []int{10, 11, 12, 13, 14, 15, 16, 17, 18, 19})
+	})
+}
+
+func TestQueue_SetGrowthFactor(t *testing.T) {
+	Convey("test Queue SetGrowthFactor", t, func() {
+		grow := func(f float64, n int) []int {
+			q := NewQueue[int]()
+			q.SetGrowthFactor(f)
+			var sizes []int
+			q.SetOnResize(func(_, newCap int) { sizes = append(sizes, newCap) })
+			for i := 0; i < n; i++ {
+				q.Push(i)
+			}
+			So(q.Size(), ShouldEqual, n)
+			So(q.Get(-1), ShouldEqual, n-1)
+			return sizes
+		}
+
+		Convey("test Queue growth factor rounds up to doubling", func() {
+			// 1.5 * 16 = 24 rounds up to 32, 1.5 * 32 = 48 to 64.
+			So(grow(1.5, 40), ShouldResemble, []int{32, 64})
+			So(grow(2, 40), ShouldResemble, []int{32, 64})
+		})
+
+		Convey("test Queue growth factor above 2", func() {
+			// 3 * 16 = 48 rounds up to 64, 3 * 64 = 192 to 256.
+			So(grow(3, 100), ShouldResemble, []int{64, 256})
+		})
+
+		Convey("test Queue growth factor invalid", func() {
+			So(func() { NewQueue[int]().SetGrowthFactor(1) }, ShouldPanicWith,
+				"queue: SetGrowthFactor() called with factor not greater than 1")
+			So(func() { NewQueue[int]().SetGrowthFactor(math.NaN()) }, ShouldPanic)
+		})
+	})
+}
+
+func TestQueue_SetOnResize(t *testing.T) {
+	Convey("test Queue SetOnResize", t, func() {
+		q := NewQueue[int]()
+		var transitions [][2]int
+		q.SetOnResize(func(oldCap, newCap int) {
+			transitions = append(transitions, [2]int{oldCap, newCap})
+		})
+		for i := 0; i < 40; i++ {
+			q.Push(i)
+		}
+		So(transitions, ShouldResemble, [][2]int{{16, 32}, {32, 64}})
+
+		for q.Size() > 4 {
+			q.Pop()
+		}
+		So(transitions, ShouldResemble, [][2]int{{16, 32}, {32, 64}, {64, 32}, {32, 16}})
+
+		q.SetOnResize(nil)
+		for i := 0; i < 40; i++ {
+			q.Push(i)
+		}
+		So(len(transitions), ShouldEqual, 4)
+	})
+}
+
+func TestQueue_PushIndexed(t *testing.T) {
+	Convey("test Queue PushIndexed", t, func() {
+		q := NewQueue[string]()
+		So(q.PushIndexed("a"), ShouldEqual, 0)
+		for i := 0; i < 20; i++ {
+			q.Push("x")
+		}
+		q.Pop()
+		// The next push wraps the ring and follows a pop.
+		i := q.PushIndexed("b")
+		So(i, ShouldEqual, 20)
+		So(q.Get(i), ShouldEqual, "b")
+		for j := 0; j < 20; j++ {
+			i = q.PushIndexed("c")
+			So(q.Get(i), ShouldEqual, "c")
+		}
+		So(i, ShouldEqual, q.Size()-1)
+	})
+}
+
+func TestQueue_TryPushPop(t *testing.T) {
+	Convey("test Queue TryPush and TryPop", t, func() {
+		q := NewBoundedQueue[int](2)
+		So(q.TryPush(1), ShouldBeTrue)
+		So(q.TryPush(2), ShouldBeTrue)
+		So(q.TryPush(3), ShouldBeFalse)
+
+		v, ok, acquired := q.TryPop()
+		So(acquired, ShouldBeTrue)
+		So(ok, ShouldBeTrue)
+		So(v, ShouldEqual, 1)
+
+		Convey("test Queue try methods under contention", func() {
+			q.lock.RLock()
+			So(q.TryPush(4), ShouldBeFalse)
+			_, ok, acquired := q.TryPop()
+			So(acquired, ShouldBeFalse)
+			So(ok, ShouldBeFalse)
+			q.lock.RUnlock()
+			So(q.Items(), ShouldResemble, []int{2})
+		})
+
+		Convey("test Queue TryPop empty", func() {
+			q.Pop()
+			_, ok, acquired := q.TryPop()
+			So(acquired, ShouldBeTrue)
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
+
+func TestQueue_Journal(t *testing.T) {
+	type job struct {
+		ID   int
+		Name string
+	}
+
+	Convey("test Queue journal", t, func() {
+		var log bytes.Buffer
+		q := NewQueue[job]()
+		q.Push(job{0, "before"})
+		q.SetJournal(&log)
+		for i := 1; i <= 3; i++ {
+			q.Push(job{i, strings.Repeat("x", i)})
+		}
+		q.Pop()
+		q.PushSeq(slices.Values([]job{{4, "seq"}}))
+		So(q.JournalErr(), ShouldBeNil)
+
+		replayed, err := ReplayQueue[job](&log)
+		So(err, ShouldBeNil)
+		// Pops are not journaled, so popped elements come back.
+		So(replayed.Items(), ShouldResemble, []job{{1, "x"}, {2, "xx"}, {3, "xxx"}, {4, "seq"}})
+
+		Convey("test Queue journal stops", func() {
+			q.SetJournal(nil)
+			q.Push(job{5, "off"})
+			So(log.Len(), ShouldEqual, 0)
+		})
+
+		Convey("test Queue journal error", func() {
+			q.SetJournal(failingWriter{})
+			q.Push(job{6, "lost"})
+			So(q.JournalErr(), ShouldEqual, errJournal)
+			So(q.Size(), ShouldEqual, 5)
+		})
+
+		Convey("test ReplayQueue corrupt log", func() {
+			var log bytes.Buffer
+			q := NewQueue[int]()
+			q.SetJournal(&log)
+			q.Push(1)
+			q.Push(2)
+			data := log.Bytes()
+			replayed, err := ReplayQueue[int](bytes.NewReader(data[:len(data)-1]))
+			So(err, ShouldNotBeNil)
+			So(replayed.Items(), ShouldResemble, []int{1})
+		})
+	})
+}
+
+var errJournal = errors.New("journal full")
+
+// failingWriter rejects every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errJournal }
+
+// checkInvariants asserts the ring buffer invariants the bitwise modulus
+// relies on.
+func checkInvariants[T comparable](q *Queue[T]) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	size := len(q.buf)
+	So(size, ShouldBeGreaterThanOrEqualTo, minQueueLen)
+	So(size&(size-1), ShouldEqual, 0)
+	So(q.count, ShouldBeBetweenOrEqual, 0, size)
+	So(q.head, ShouldBeBetweenOrEqual, 0, size-1)
+	So(q.tail, ShouldEqual, (q.head+q.count)&(size-1))
+}
+
+func TestQueue_Sizing(t *testing.T) {
+	Convey("test Queue sizing", t, func() {
+		Convey("test nextPow2", func() {
+			for n, want := range map[int]int{-1: 16, 0: 16, 1: 16, 16: 16, 17: 32, 1000: 1024, 1024: 1024} {
+				So(nextPow2(n), ShouldEqual, want)
+			}
+		})
+
+		Convey("test NewQueueWithCapacity", func() {
+			for _, capacity := range []int{0, 1, 16, 17, 100} {
+				q := NewQueueWithCapacity[int](capacity)
+				checkInvariants(q)
+				So(len(q.buf), ShouldEqual, nextPow2(capacity))
+				for i := 0; i < capacity; i++ {
+					q.Push(i)
+				}
+				So(len(q.buf), ShouldEqual, nextPow2(capacity))
+				checkInvariants(q)
+			}
+			q := NewQueueWithCapacity[int](1024)
+			q.Push(1)
+			q.Pop()
+			So(len(q.buf), ShouldEqual, 1024)
+			for i := 0; i < 5000; i++ {
+				q.Push(i)
+			}
+			for q.Size() > 1 {
+				q.Pop()
+			}
+			So(len(q.buf), ShouldEqual, 1024)
+			q.Push(1)
+			q.SwapOut()
+			So(len(q.buf), ShouldEqual, 1024)
+			checkInvariants(q)
+			So(func() { NewQueueWithCapacity[int](-1) }, ShouldPanicWith, "queue: NewQueueWithCapacity() called with negative capacity")
+		})
+
+		Convey("test NewQueueFromSlice", func() {
+			for _, n := range []int{0, 5, 16, 33} {
+				checkInvariants(NewQueueFromSlice(make([]int, n)))
+			}
+		})
+
+		Convey("test Queue Reserve", func() {
+			q := NewQueue[int]()
+			for i := 1; i <= 10; i++ {
+				q.Push(i)
+				q.Push(i)
+				q.Pop()
+			}
+			q.Reserve(0)
+			So(len(q.buf), ShouldEqual, minQueueLen)
+			q.Reserve(100)
+			So(len(q.buf), ShouldEqual, 128)
+			checkInvariants(q)
+			So(q.Items(), ShouldResemble, []int{6, 6, 7, 7, 8, 8, 9, 9, 10, 10})
+			q.Reserve(100)
+			So(len(q.buf), ShouldEqual, 128)
+
+			for i := 0; i < 1000; i++ {
+				q.Push(i)
+				checkInvariants(q)
+			}
+			for !q.Empty() {
+				q.PopN(37)
+				checkInvariants(q)
+			}
+		})
+	})
+}
+
+func TestQueue_AtomicSize(t *testing.T) {
+	Convey("test Queue atomic Size", t, func() {
+		q := NewQueueFromSlice([]int{1, 2, 3})
+		So(q.Size(), ShouldEqual, 3)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 5000; j++ {
+					q.Push(j)
+					if j%3 == i%3 {
+						q.Pop()
+					}
+					if j%100 == 0 {
+						q.Dedup()
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+		q.lock.RLock()
+		count := q.count
+		q.lock.RUnlock()
+		So(q.Size(), ShouldEqual, count)
+		So(len(q.Items()), ShouldEqual, count)
+	})
+}
+
+func BenchmarkQueue_Size(b *testing.B) {
+	q := NewQueue[int]()
+	stop := make(chan struct{})
+	defer close(stop)
+	// A writer keeps the lock busy while readers poll the depth.
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				q.Push(1)
+				q.Pop()
+			}
+		}
+	}()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = q.Size()
+		}
+	})
+}
+
+func TestQueue_SwapOut(t *testing.T) {
+	Convey("test Queue SwapOut", t, func() {
+		Convey("test Queue SwapOut contiguous", func() {
+			q := NewQueue[int]()
+			for i := 0; i < 100; i++ {
+				q.Push(i)
+			}
+			q.Pop()
+			buf := q.buf
+			items := q.SwapOut()
+			So(len(items), ShouldEqual, 99)
+			So(items[0], ShouldEqual, 1)
+			So(items[98], ShouldEqual, 99)
+			// The old buffer is handed over without copying.
+			So(&items[0], ShouldEqual, &buf[1])
+			So(cap(items), ShouldEqual, 99)
+			So(q.Empty(), ShouldBeTrue)
+			So(len(q.buf), ShouldEqual, minQueueLen)
+			checkInvariants(q)
+
+			q.Push(7)
+			So(items[0], ShouldEqual, 1)
+			So(q.Items(), ShouldResemble, []int{7})
+		})
+
+		Convey("test Queue SwapOut wrapped", func() {
+			q := NewQueue[int]()
+			for i := 0; i < 12; i++ {
+				q.Push(i)
+			}
+			for i := 12; i < 20; i++ {
+				q.Pop()
+				q.Push(i)
+			}
+			So(q.SwapOut(), ShouldResemble, []int{8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19})
+			So(q.Size(), ShouldEqual, 0)
+			So(q.SwapOut(), ShouldBeEmpty)
+		})
+
+		Convey("test Queue SwapOut frees bounded slots", func() {
+			q := NewBoundedQueue[int](2)
+			q.Push(1)
+			q.Push(2)
+			done := make(chan struct{})
+			go func() {
+				q.Push(3)
+				close(done)
+			}()
+			time.Sleep(10 * time.Millisecond)
+			So(q.SwapOut(), ShouldResemble, []int{1, 2})
+			<-done
+			So(q.Items(), ShouldResemble, []int{3})
+		})
+	})
+}
+
+func TestQueue_Watermarks(t *testing.T) {
+	Convey("test Queue watermarks", t, func() {
+		q := NewQueue[int]()
+		var highs, lows int
+		var seen []int
+		// The callbacks run outside the lock, so the queue is usable.
+		q.SetWatermarks(8, 2, func() {
+			highs++
+			seen = append(seen, len(q.Items()))
+		}, func() {
+			lows++
+			seen = append(seen, len(q.Items()))
+		})
+
+		for i := 0; i < 7; i++ {
+			q.Push(i)
+		}
+		So(highs, ShouldEqual, 0)
+		q.Push(7)
+		So(highs, ShouldEqual, 1)
+		for i := 8; i < 12; i++ {
+			q.Push(i)
+		}
+		// Hovering between the watermarks fires nothing.
+		q.PopN(5)
+		q.Push(12)
+		q.Push(13)
+		So(highs, ShouldEqual, 1)
+		So(lows, ShouldEqual, 0)
+
+		for q.Size() > 2 {
+			q.Pop()
+		}
+		So(lows, ShouldEqual, 1)
+		q.Pop()
+		q.Pop()
+		So(highs, ShouldEqual, 1)
+		So(lows, ShouldEqual, 1)
+		So(seen, ShouldResemble, []int{8, 2})
+
+		Convey("test Queue watermarks fire again after a full cycle", func() {
+			q.PushSeq(slices.Values(make([]int, 10)))
+			So(highs, ShouldEqual, 2)
+			q.SwapOut()
+			So(lows, ShouldEqual, 2)
+		})
+
+		Convey("test Queue watermarks concurrent", func() {
+			q := NewQueue[int]()
+			var (
+				inFlight atomic.Int32
+				mu       sync.Mutex
+				events   []string
+			)
+			record := func(ev string) func() {
+				return func() {
+					if inFlight.Add(1) != 1 {
+						panic("watermark callbacks overlap")
+					}
+					mu.Lock()
+					events = append(events, ev)
+					mu.Unlock()
+					// Callbacks may use the queue, and cross watermarks again.
+					q.Size()
+					inFlight.Add(-1)
+				}
+			}
+			q.SetWatermarks(6, 2, record("high"), record("low"))
+
+			var wg sync.WaitGroup
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < 2000; i++ {
+						if i%3 == 2 {
+							q.Pop()
+						} else {
+							q.Push(i)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			for q.Size() > 0 {
+				q.Pop()
+			}
+			So(len(events), ShouldBeGreaterThan, 0)
+			So(len(events)%2, ShouldEqual, 0)
+			for i, ev := range events {
+				So(ev, ShouldEqual, []string{"high", "low"}[i%2])
+			}
+		})
+
+		Convey("test Queue watermarks invalid", func() {
+			So(func() { q.SetWatermarks(2, 2, nil, nil) }, ShouldPanic)
+			So(func() { q.SetWatermarks(2, -1, nil, nil) }, ShouldPanic)
+		})
+	})
+}
+
+func TestQueue_ReplayBuffer(t *testing.T) {
+	Convey("test Queue replay buffer", t, func() {
+		q := NewQueue[int]()
+		for i := 0; i < 20; i++ {
+			q.Push(i)
+		}
+		q.Pop()
+		So(q.RecentlyPopped(), ShouldBeNil)
+
+		q.EnableReplayBuffer(4)
+		So(q.RecentlyPopped(), ShouldBeEmpty)
+		q.Pop()
+		q.Pop()
+		So(q.RecentlyPopped(), ShouldResemble, []int{1, 2})
+		for i := 0; i < 5; i++ {
+			q.Pop()
+		}
+		q.PopN(3)
+		So(q.RecentlyPopped(), ShouldResemble, []int{7, 8, 9, 10})
+		So(q.Size(), ShouldEqual, 9)
+
+		Convey("test Queue replay buffer reset and off", func() {
+			q.EnableReplayBuffer(2)
+			So(q.RecentlyPopped(), ShouldBeEmpty)
+			q.Pop()
+			So(q.RecentlyPopped(), ShouldResemble, []int{11})
+			q.EnableReplayBuffer(0)
+			q.Pop()
+			So(q.RecentlyPopped(), ShouldBeNil)
+			So(func() { q.EnableReplayBuffer(-1) }, ShouldPanic)
+		})
+	})
+}
+
+func TestQueue_Chunks(t *testing.T) {
+	Convey("test Queue Chunks", t, func() {
+		q := NewQueue[int]()
+		for i := 0; i < 10; i++ {
+			q.Push(i)
+		}
+		var chunks [][]int
+		for c := range q.Chunks(3) {
+			chunks = append(chunks, c)
+			q.Push(-1)
+		}
+		So(chunks, ShouldResemble, [][]int{{0, 1, 2}, {3, 4, 5}, {6, 7, 8}, {9}})
+		So(q.Size(), ShouldEqual, 14)
+
+		n := 0
+		for range q.Chunks(100) {
+			n++
+			break
+		}
+		So(n, ShouldEqual, 1)
+		for range NewQueue[int]().Chunks(1) {
+			n++
+		}
+		So(n, ShouldEqual, 1)
+		So(func() { q.Chunks(0) }, ShouldPanic)
+	})
+}
+
 func push() {
 	for i := 0; i != kPushingNum; i++ {
 		lfq.Push(i)
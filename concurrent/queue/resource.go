@@ -0,0 +1,86 @@
+package queue
+
+import "context"
+
+// ResourcePool hands out a fixed set of reusable resources, such as
+// connections, backed by a bounded Queue of the idle ones. Resources are
+// boxed, since Queue needs comparable elements.
+type ResourcePool[T any] struct {
+	q *Queue[*T]
+}
+
+// NewResourcePool constructs and returns a new ResourcePool holding
+// resources, all idle. It panics if resources is empty.
+func NewResourcePool[T any](resources []T) *ResourcePool[T] {
+	if len(resources) == 0 {
+		panic("queue: NewResourcePool() called with no resources")
+	}
+	p := &ResourcePool[T]{q: NewBoundedQueue[*T](len(resources))}
+	for _, r := range resources {
+		p.q.Push(&r)
+	}
+	return p
+}
+
+// Get takes an idle resource, blocking until one is put back if there is
+// none. It returns ctx.Err() if ctx is done first, or ErrClosed once the pool
+// is closed.
+func (p *ResourcePool[T]) Get(ctx context.Context) (T, error) {
+	q := p.q
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	var v T
+	if q.count <= 0 && !q.closed {
+		defer q.recordWait(q.waitStart(), &q.consumerWait)
+		defer q.wakeOnDone(ctx, q.notEmpty)()
+		for q.count <= 0 && !q.closed {
+			if err := ctx.Err(); err != nil {
+				return v, err
+			}
+			q.notEmpty.Wait()
+		}
+	}
+	if q.closed {
+		return v, ErrClosed
+	}
+	r, _ := q.pop()
+	return *r, nil
+}
+
+// Put returns a resource taken by Get to the pool. It panics if every
+// resource is already idle, which means r was put back twice or never taken.
+// After Close, r is dropped.
+func (p *ResourcePool[T]) Put(r T) {
+	q := p.q
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return
+	}
+	if q.full() {
+		panic("queue: Put() called on full pool")
+	}
+	q.push(&r)
+}
+
+// Close closes the pool, failing waiting and future Gets with ErrClosed, and
+// returns the idle resources so that the caller can release them. Resources
+// still taken are dropped when put back.
+func (p *ResourcePool[T]) Close() []T {
+	q := p.q
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.closed = true
+	idle := make([]T, 0, q.count)
+	for q.count > 0 {
+		r, _ := q.pop()
+		idle = append(idle, *r)
+	}
+	q.notEmpty.Broadcast()
+	return idle
+}
+
+// Idle returns the number of resources available to Get.
+func (p *ResourcePool[T]) Idle() int {
+	return p.q.Size()
+}
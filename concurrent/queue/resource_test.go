@@ -0,0 +1,75 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestResourcePool(t *testing.T) {
+	Convey("test ResourcePool", t, func() {
+		p := NewResourcePool([]string{"a", "b"})
+		ctx := context.Background()
+		a, err := p.Get(ctx)
+		So(err, ShouldBeNil)
+		So(a, ShouldEqual, "a")
+		b, err := p.Get(ctx)
+		So(err, ShouldBeNil)
+		So(b, ShouldEqual, "b")
+		So(p.Idle(), ShouldEqual, 0)
+
+		Convey("test ResourcePool Get blocks until Put", func() {
+			got := make(chan string)
+			go func() {
+				r, _ := p.Get(ctx)
+				got <- r
+			}()
+			select {
+			case <-got:
+				t.Fatal("Get did not block on an exhausted pool")
+			case <-time.After(20 * time.Millisecond):
+			}
+			p.Put(b)
+			So(<-got, ShouldEqual, "b")
+		})
+
+		Convey("test ResourcePool Get cancelled", func() {
+			ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+			_, err := p.Get(ctx)
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+			p.Put(a)
+			So(p.Idle(), ShouldEqual, 1)
+		})
+
+		Convey("test ResourcePool Put on full pool", func() {
+			p.Put(a)
+			p.Put(b)
+			So(func() { p.Put(a) }, ShouldPanicWith, "queue: Put() called on full pool")
+		})
+
+		Convey("test ResourcePool Close", func() {
+			errs := make(chan error)
+			go func() {
+				_, err := p.Get(ctx)
+				errs <- err
+			}()
+			time.Sleep(20 * time.Millisecond)
+			So(p.Close(), ShouldBeEmpty)
+			So(<-errs, ShouldEqual, ErrClosed)
+
+			p.Put(b)
+			So(p.Idle(), ShouldEqual, 0)
+			_, err := p.Get(ctx)
+			So(err, ShouldEqual, ErrClosed)
+		})
+
+		Convey("test ResourcePool Close returns idle resources", func() {
+			p.Put(b)
+			So(p.Close(), ShouldResemble, []string{"b"})
+		})
+	})
+}
@@ -0,0 +1,67 @@
+package queue
+
+import "sync"
+
+// RingLog is a fixed-size ring buffer keeping the most recent elements pushed
+// into it. Once full, every Push overwrites the oldest element; the buffer is
+// never resized.
+type RingLog[T any] struct {
+	buf         []T
+	head, count int
+	lock        sync.RWMutex
+}
+
+// NewRingLog constructs and returns a new RingLog retaining at most capacity
+// elements. This call panics if capacity is not positive.
+func NewRingLog[T any](capacity int) *RingLog[T] {
+	if capacity <= 0 {
+		panic("queue: NewRingLog() called with non-positive capacity")
+	}
+	return &RingLog[T]{
+		buf: make([]T, capacity),
+	}
+}
+
+// Cap returns the maximum number of elements the log retains.
+func (r *RingLog[T]) Cap() int {
+	return len(r.buf)
+}
+
+// Size returns the number of elements currently stored in the log.
+func (r *RingLog[T]) Size() int {
+	r.lock.RLock()
+	count := r.count
+	r.lock.RUnlock()
+	return count
+}
+
+// Push appends elem to the log, discarding the oldest element if the log is full.
+func (r *RingLog[T]) Push(elem T) {
+	r.lock.Lock()
+	r.push(elem)
+	r.lock.Unlock()
+}
+
+// push appends elem and returns the element it overwrote and true, if the log
+// was full. The caller must hold the write lock.
+func (r *RingLog[T]) push(elem T) (evicted T, ok bool) {
+	if r.count == len(r.buf) {
+		evicted, ok = r.buf[r.head], true
+		r.buf[r.head] = elem
+		r.head = (r.head + 1) % len(r.buf)
+	} else {
+		r.buf[(r.head+r.count)%len(r.buf)] = elem
+		r.count++
+	}
+	return evicted, ok
+}
+
+// ToSlice returns a copy of the log contents ordered from oldest to newest.
+func (r *RingLog[T]) ToSlice() []T {
+	r.lock.RLock()
+	items := make([]T, r.count)
+	n := copy(items, r.buf[r.head:])
+	copy(items[n:], r.buf[:r.count-n])
+	r.lock.RUnlock()
+	return items
+}
@@ -0,0 +1,35 @@
+package queue
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRingLog(t *testing.T) {
+	Convey("test RingLog", t, func() {
+		Convey("test RingLog below capacity", func() {
+			r := NewRingLog[int](4)
+			r.Push(1)
+			r.Push(2)
+			So(r.Size(), ShouldEqual, 2)
+			So(r.ToSlice(), ShouldResemble, []int{1, 2})
+		})
+
+		Convey("test RingLog overwrites oldest", func() {
+			r := NewRingLog[int](3)
+			for i := 0; i < 10; i++ {
+				r.Push(i)
+			}
+			So(r.Size(), ShouldEqual, 3)
+			So(r.Cap(), ShouldEqual, 3)
+			So(r.ToSlice(), ShouldResemble, []int{7, 8, 9})
+		})
+
+		Convey("test RingLog empty", func() {
+			r := NewRingLog[int](3)
+			So(r.ToSlice(), ShouldBeEmpty)
+			So(func() { NewRingLog[int](0) }, ShouldPanic)
+		})
+	})
+}
@@ -0,0 +1,77 @@
+package queue
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// SelectPop pops an element from the first non-empty queue among qs and
+// returns it along with the index of the queue it came from. The scan starts
+// at a random queue on every call and wraps around, so with equal loads each
+// queue is served equally often over repeated calls, and none is starved by
+// the ones before it. No state is shared between calls. Only one queue is
+// locked at a time, so SelectPop cannot deadlock against other callers. It
+// returns ok=false if every queue was empty.
+func SelectPop[T comparable](qs ...*Queue[T]) (val T, idx int, ok bool) {
+	if len(qs) == 0 {
+		return val, -1, false
+	}
+	start := rand.IntN(len(qs))
+	for i := range qs {
+		idx = (start + i) % len(qs)
+		if val, ok = qs[idx].Pop(); ok {
+			return val, idx, true
+		}
+	}
+	return val, -1, false
+}
+
+// QueueGroup owns several queues and pops from them in strict rotation: each
+// Pop starts at the queue after the one the previous Pop took from, so equal
+// loads are drained evenly and no queue is starved by the ones added before
+// it.
+type QueueGroup[T comparable] struct {
+	lock   sync.Mutex
+	qs     []*Queue[T]
+	cursor int
+}
+
+// NewQueueGroup constructs and returns a new QueueGroup holding qs.
+func NewQueueGroup[T comparable](qs ...*Queue[T]) *QueueGroup[T] {
+	return &QueueGroup[T]{qs: qs}
+}
+
+// Add appends q to the rotation.
+func (g *QueueGroup[T]) Add(q *Queue[T]) {
+	g.lock.Lock()
+	g.qs = append(g.qs, q)
+	g.lock.Unlock()
+}
+
+// Pop pops an element from the next non-empty queue in rotation and returns
+// it along with the index of the queue it came from, in the order the queues
+// were added. It returns ok=false if every queue was empty. Pops are
+// serialized by the group, while the queues themselves stay usable directly.
+func (g *QueueGroup[T]) Pop() (val T, idx int, ok bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	for i := range g.qs {
+		idx = (g.cursor + i) % len(g.qs)
+		if val, ok = g.qs[idx].Pop(); ok {
+			g.cursor = (idx + 1) % len(g.qs)
+			return val, idx, true
+		}
+	}
+	return val, -1, false
+}
+
+// TotalLen returns the number of elements across all queues.
+func (g *QueueGroup[T]) TotalLen() int {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	total := 0
+	for _, q := range g.qs {
+		total += q.Size()
+	}
+	return total
+}
@@ -0,0 +1,120 @@
+package queue
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSelectPop(t *testing.T) {
+	Convey("test SelectPop", t, func() {
+		Convey("test SelectPop all empty", func() {
+			_, idx, ok := SelectPop(NewQueue[int](), NewQueue[int]())
+			So(ok, ShouldBeFalse)
+			So(idx, ShouldEqual, -1)
+			_, _, ok = SelectPop[int]()
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("test SelectPop skips empty queues", func() {
+			qs := []*Queue[int]{NewQueue[int](), NewQueue[int](), NewQueue[int]()}
+			qs[1].Push(10)
+			qs[1].Push(11)
+			for _, want := range []int{10, 11} {
+				v, idx, ok := SelectPop(qs...)
+				So(ok, ShouldBeTrue)
+				So(idx, ShouldEqual, 1)
+				So(v, ShouldEqual, want)
+			}
+			_, _, ok := SelectPop(qs...)
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("test SelectPop fairness", func() {
+			qs := []*Queue[int]{NewQueue[int](), NewQueue[int](), NewQueue[int]()}
+			for i, q := range qs {
+				for j := 0; j < 3000; j++ {
+					q.Push(i)
+				}
+			}
+			counts := make([]int, len(qs))
+			for i := 0; i < 3000; i++ {
+				v, idx, ok := SelectPop(qs...)
+				So(ok, ShouldBeTrue)
+				So(v, ShouldEqual, idx)
+				counts[idx]++
+			}
+			// Each queue expects 1000 pops; the bounds are over 10 standard
+			// deviations wide.
+			for _, n := range counts {
+				So(n, ShouldBeBetween, 750, 1250)
+			}
+		})
+
+		Convey("test SelectPop alternating callers", func() {
+			// Two callers with different queue sets must not lock each other
+			// into the same start offset.
+			a := []*Queue[int]{NewQueue[int](), NewQueue[int]()}
+			b := []*Queue[int]{NewQueue[int](), NewQueue[int](), NewQueue[int]()}
+			for _, q := range append(a, b...) {
+				for j := 0; j < 100; j++ {
+					q.Push(j)
+				}
+			}
+			counts := make([]int, len(a))
+			for i := 0; i < 100; i++ {
+				_, idx, _ := SelectPop(a...)
+				counts[idx]++
+				SelectPop(b...)
+			}
+			So(counts[0], ShouldBeGreaterThan, 0)
+			So(counts[1], ShouldBeGreaterThan, 0)
+		})
+	})
+}
+
+func TestQueueGroup(t *testing.T) {
+	Convey("test QueueGroup", t, func() {
+		g := NewQueueGroup[int]()
+		_, idx, ok := g.Pop()
+		So(ok, ShouldBeFalse)
+		So(idx, ShouldEqual, -1)
+
+		for i := 0; i < 4; i++ {
+			q := NewQueue[int]()
+			for j := 0; j < 100; j++ {
+				q.Push(i*100 + j)
+			}
+			g.Add(q)
+		}
+		So(g.TotalLen(), ShouldEqual, 400)
+
+		Convey("test QueueGroup round-robin", func() {
+			counts := make([]int, 4)
+			for i := 0; i < 200; i++ {
+				v, idx, ok := g.Pop()
+				So(ok, ShouldBeTrue)
+				So(idx, ShouldEqual, i%4)
+				So(v, ShouldEqual, idx*100+i/4)
+				counts[idx]++
+			}
+			So(counts, ShouldResemble, []int{50, 50, 50, 50})
+			So(g.TotalLen(), ShouldEqual, 200)
+		})
+
+		Convey("test QueueGroup skips drained queues", func() {
+			for g.TotalLen() > 0 {
+				g.Pop()
+			}
+			g.qs[2].Push(7)
+			g.qs[0].Push(8)
+			v, idx, ok := g.Pop()
+			So(ok, ShouldBeTrue)
+			So(idx, ShouldEqual, 0)
+			So(v, ShouldEqual, 8)
+			v, idx, _ = g.Pop()
+			So(idx, ShouldEqual, 2)
+			So(v, ShouldEqual, 7)
+		})
+	})
+}
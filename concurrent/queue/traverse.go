@@ -0,0 +1,19 @@
+package queue
+
+// BFS walks the graph reachable from start breadth-first, calling visit on
+// each node once, in the order the nodes are discovered. The walk stops as
+// soon as visit returns false. neighbors returns the nodes adjacent to a
+// node; nodes already discovered are skipped, so cycles are safe.
+func BFS[T comparable](start T, neighbors func(T) []T, visit func(T) bool) {
+	frontier := NewUniqueQueue[T](NeverReadmit)
+	frontier.Push(start)
+	for {
+		node, ok := frontier.Pop()
+		if !ok || !visit(node) {
+			return
+		}
+		for _, next := range neighbors(node) {
+			frontier.Push(next)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package queue
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBFS(t *testing.T) {
+	Convey("test BFS", t, func() {
+		graph := map[string][]string{
+			"a": {"b", "c"},
+			"b": {"d", "a"},
+			"c": {"d", "e"},
+			"d": {"f", "b"},
+			"e": {"c"},
+			"f": {"a"},
+			"x": {"a"},
+		}
+		neighbors := func(n string) []string { return graph[n] }
+
+		var order []string
+		BFS("a", neighbors, func(n string) bool {
+			order = append(order, n)
+			return true
+		})
+		So(order, ShouldResemble, []string{"a", "b", "c", "d", "e", "f"})
+
+		Convey("test BFS stops early", func() {
+			order = nil
+			BFS("a", neighbors, func(n string) bool {
+				order = append(order, n)
+				return n != "c"
+			})
+			So(order, ShouldResemble, []string{"a", "b", "c"})
+		})
+
+		Convey("test BFS isolated start", func() {
+			order = nil
+			BFS("z", neighbors, func(n string) bool {
+				order = append(order, n)
+				return true
+			})
+			So(order, ShouldResemble, []string{"z"})
+		})
+	})
+}
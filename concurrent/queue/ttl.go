@@ -0,0 +1,82 @@
+package queue
+
+import "time"
+
+// ttlEntry is an element of a TTLQueue along with its insertion time.
+type ttlEntry[T comparable] struct {
+	elem T
+	at   time.Time
+}
+
+// TTLQueue is a Queue whose elements expire once they are older than its TTL.
+// Expired elements are dropped from the head by Evict, and automatically by
+// Pop, Peek and Len.
+type TTLQueue[T comparable] struct {
+	q   *Queue[ttlEntry[T]]
+	ttl time.Duration
+	// now returns the current time, it is replaced by tests with a fake clock.
+	now func() time.Time
+}
+
+// NewTTLQueue constructs and returns a new TTLQueue dropping elements older than ttl.
+func NewTTLQueue[T comparable](ttl time.Duration) *TTLQueue[T] {
+	return &TTLQueue[T]{
+		q:   NewQueue[ttlEntry[T]](),
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// evict drops expired elements from the head, the caller must hold the write lock.
+func (t *TTLQueue[T]) evict(now time.Time) (n int) {
+	for t.q.count > 0 && now.Sub(t.q.buf[t.q.head].at) > t.ttl {
+		t.q.pop()
+		n++
+	}
+	return
+}
+
+// Evict removes all elements older than the TTL as of now, returning how
+// many were removed. Elements are pushed in time order, so only the head
+// needs to be examined.
+func (t *TTLQueue[T]) Evict(now time.Time) int {
+	t.q.lock.Lock()
+	defer t.q.lock.Unlock()
+	return t.evict(now)
+}
+
+// Push puts an element on the end of the queue, stamped with the current time.
+func (t *TTLQueue[T]) Push(elem T) {
+	t.q.Push(ttlEntry[T]{elem: elem, at: t.now()})
+}
+
+// Pop removes and returns the oldest unexpired element and true, or a default
+// value and false if no such element exists.
+func (t *TTLQueue[T]) Pop() (T, bool) {
+	t.q.lock.Lock()
+	defer t.q.lock.Unlock()
+	t.evict(t.now())
+	e, ok := t.q.pop()
+	return e.elem, ok
+}
+
+// Peek returns the oldest unexpired element and true without removing it, or
+// a default value and false if no such element exists.
+func (t *TTLQueue[T]) Peek() (T, bool) {
+	t.q.lock.Lock()
+	defer t.q.lock.Unlock()
+	t.evict(t.now())
+	if t.q.count <= 0 {
+		var v T
+		return v, false
+	}
+	return t.q.buf[t.q.head].elem, true
+}
+
+// Len returns the number of unexpired elements in the queue.
+func (t *TTLQueue[T]) Len() int {
+	t.q.lock.Lock()
+	defer t.q.lock.Unlock()
+	t.evict(t.now())
+	return t.q.count
+}
@@ -0,0 +1,50 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTTLQueue(t *testing.T) {
+	Convey("test TTLQueue", t, func() {
+		clock := time.Unix(0, 0)
+		q := NewTTLQueue[string](10 * time.Second)
+		q.now = func() time.Time { return clock }
+
+		q.Push("a")
+		clock = clock.Add(4 * time.Second)
+		q.Push("b")
+		clock = clock.Add(4 * time.Second)
+		q.Push("c")
+
+		Convey("test TTLQueue nothing expired", func() {
+			So(q.Len(), ShouldEqual, 3)
+			v, ok := q.Peek()
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, "a")
+		})
+
+		Convey("test TTLQueue entries expire in order", func() {
+			clock = clock.Add(3 * time.Second)
+			So(q.Len(), ShouldEqual, 2)
+			v, ok := q.Pop()
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, "b")
+
+			clock = clock.Add(10 * time.Second)
+			_, ok = q.Peek()
+			So(ok, ShouldBeFalse)
+			_, ok = q.Pop()
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("test TTLQueue Evict", func() {
+			So(q.Evict(clock.Add(2*time.Second+1)), ShouldEqual, 1)
+			So(q.Evict(clock.Add(2*time.Second+1)), ShouldEqual, 0)
+			So(q.Evict(clock.Add(time.Hour)), ShouldEqual, 2)
+			So(q.Len(), ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,66 @@
+package queue
+
+// UniquePolicy decides when a UniqueQueue admits a value again.
+type UniquePolicy int
+
+const (
+	// ReadmitAfterPop rejects values currently queued, and admits them again once popped.
+	ReadmitAfterPop UniquePolicy = iota
+	// NeverReadmit rejects every value ever pushed, as for a visited set.
+	NeverReadmit
+)
+
+// UniqueQueue is a Queue paired with a membership set, so duplicate pushes
+// are rejected in O(1).
+type UniqueQueue[T comparable] struct {
+	q      *Queue[T]
+	set    map[T]struct{}
+	policy UniquePolicy
+}
+
+// NewUniqueQueue constructs and returns a new UniqueQueue using policy.
+func NewUniqueQueue[T comparable](policy UniquePolicy) *UniqueQueue[T] {
+	return &UniqueQueue[T]{
+		q:      NewQueue[T](),
+		set:    make(map[T]struct{}),
+		policy: policy,
+	}
+}
+
+// Push puts an element on the end of the queue and returns true, or returns
+// false if the policy rejects it as a duplicate.
+func (u *UniqueQueue[T]) Push(elem T) bool {
+	u.q.lock.Lock()
+	defer u.q.lock.Unlock()
+	if _, ok := u.set[elem]; ok {
+		return false
+	}
+	u.set[elem] = struct{}{}
+	u.q.push(elem)
+	return true
+}
+
+// Pop removes and returns the element from the front of the queue and true,
+// or a default value and false if the queue is empty.
+func (u *UniqueQueue[T]) Pop() (T, bool) {
+	u.q.lock.Lock()
+	defer u.q.lock.Unlock()
+	v, ok := u.q.pop()
+	if ok && u.policy == ReadmitAfterPop {
+		delete(u.set, v)
+	}
+	return v, ok
+}
+
+// Contains reports whether Push would reject elem.
+func (u *UniqueQueue[T]) Contains(elem T) bool {
+	u.q.lock.RLock()
+	_, ok := u.set[elem]
+	u.q.lock.RUnlock()
+	return ok
+}
+
+// Size returns the number of elements currently stored in the queue.
+func (u *UniqueQueue[T]) Size() int {
+	return u.q.Size()
+}
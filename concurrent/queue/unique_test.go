@@ -0,0 +1,45 @@
+package queue
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUniqueQueue(t *testing.T) {
+	Convey("test UniqueQueue", t, func() {
+		Convey("test UniqueQueue ReadmitAfterPop", func() {
+			u := NewUniqueQueue[string](ReadmitAfterPop)
+			So(u.Push("a"), ShouldBeTrue)
+			So(u.Push("b"), ShouldBeTrue)
+			So(u.Push("a"), ShouldBeFalse)
+			So(u.Size(), ShouldEqual, 2)
+			So(len(u.set), ShouldEqual, u.Size())
+
+			v, ok := u.Pop()
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, "a")
+			So(u.Contains("a"), ShouldBeFalse)
+			So(len(u.set), ShouldEqual, u.Size())
+			So(u.Push("a"), ShouldBeTrue)
+			So(u.q.Items(), ShouldResemble, []string{"b", "a"})
+		})
+
+		Convey("test UniqueQueue NeverReadmit", func() {
+			u := NewUniqueQueue[string](NeverReadmit)
+			So(u.Push("a"), ShouldBeTrue)
+			So(u.Push("b"), ShouldBeTrue)
+			u.Pop()
+			So(u.Contains("a"), ShouldBeTrue)
+			So(u.Push("a"), ShouldBeFalse)
+			So(u.Push("c"), ShouldBeTrue)
+			So(u.q.Items(), ShouldResemble, []string{"b", "c"})
+			So(len(u.set), ShouldEqual, 3)
+
+			u.Pop()
+			u.Pop()
+			_, ok := u.Pop()
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,38 @@
+package queue
+
+import "weak"
+
+// WeakQueue is a Queue holding weak references, for queues such as eviction
+// lists which should not on their own keep their elements alive.
+//
+// Semantics: pushing a pointer does not keep its target reachable. Once the
+// last strong reference elsewhere is dropped, the garbage collector may free
+// the target while it is still queued, and the entry then pops as nil. The
+// entry itself stays in the queue until popped, so Size counts collected
+// entries too, and callers must check every popped pointer for nil.
+type WeakQueue[T any] struct {
+	q *Queue[weak.Pointer[T]]
+}
+
+// NewWeakQueue constructs and returns a new WeakQueue.
+func NewWeakQueue[T any]() *WeakQueue[T] {
+	return &WeakQueue[T]{q: NewQueue[weak.Pointer[T]]()}
+}
+
+// Push puts a weak reference to v on the end of the queue.
+func (w *WeakQueue[T]) Push(v *T) {
+	w.q.Push(weak.Make(v))
+}
+
+// Pop removes the entry at the front of the queue and returns its target and
+// true, or nil and false if the queue is empty. The target is nil if it has
+// been collected.
+func (w *WeakQueue[T]) Pop() (*T, bool) {
+	p, ok := w.q.Pop()
+	return p.Value(), ok
+}
+
+// Size returns the number of entries in the queue, collected or not.
+func (w *WeakQueue[T]) Size() int {
+	return w.q.Size()
+}
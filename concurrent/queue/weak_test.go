@@ -0,0 +1,40 @@
+package queue
+
+import (
+	"runtime"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWeakQueue(t *testing.T) {
+	Convey("test WeakQueue", t, func() {
+		w := NewWeakQueue[[1 << 10]byte]()
+		_, ok := w.Pop()
+		So(ok, ShouldBeFalse)
+
+		kept := new([1 << 10]byte)
+		kept[0] = 1
+		w.Push(kept)
+		w.Push(new([1 << 10]byte))
+		w.Push(nil)
+		So(w.Size(), ShouldEqual, 3)
+
+		runtime.GC()
+		v, ok := w.Pop()
+		So(ok, ShouldBeTrue)
+		So(v, ShouldEqual, kept)
+		So(v[0], ShouldEqual, 1)
+
+		// Nothing but the queue referenced the second value.
+		v, ok = w.Pop()
+		So(ok, ShouldBeTrue)
+		So(v, ShouldBeNil)
+
+		v, ok = w.Pop()
+		So(ok, ShouldBeTrue)
+		So(v, ShouldBeNil)
+		So(w.Size(), ShouldEqual, 0)
+		runtime.KeepAlive(kept)
+	})
+}
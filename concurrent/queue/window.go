@@ -0,0 +1,63 @@
+package queue
+
+// SlidingWindow aggregates the last N samples pushed into it, keeping them in
+// a RingLog. The aggregate starts from the zero value and is updated
+// incrementally: add folds in each new sample, and remove takes out each
+// sample falling off the window, so it must undo add. That suits sums and
+// counts, and averages kept as a (sum, count) pair. Aggregates which cannot be
+// undone, such as min and max, are supported by passing a nil remove, in which
+// case the aggregate is refolded from the remaining samples on every eviction,
+// in O(N).
+type SlidingWindow[T any] struct {
+	log         *RingLog[T]
+	acc         T
+	add, remove func(acc, x T) T
+}
+
+// NewSlidingWindow constructs and returns a new, empty SlidingWindow over the
+// last size samples. This call panics if size is not positive or add is nil.
+func NewSlidingWindow[T any](size int, add, remove func(acc, x T) T) *SlidingWindow[T] {
+	if size <= 0 {
+		panic("queue: NewSlidingWindow() called with non-positive size")
+	}
+	if add == nil {
+		panic("queue: NewSlidingWindow() called with nil add")
+	}
+	return &SlidingWindow[T]{
+		log:    NewRingLog[T](size),
+		add:    add,
+		remove: remove,
+	}
+}
+
+// Push adds x to the window, evicting the oldest sample if the window is
+// full, and updates the aggregate.
+func (w *SlidingWindow[T]) Push(x T) {
+	w.log.lock.Lock()
+	defer w.log.lock.Unlock()
+	evicted, ok := w.log.push(x)
+	switch {
+	case !ok:
+		w.acc = w.add(w.acc, x)
+	case w.remove != nil:
+		w.acc = w.add(w.remove(w.acc, evicted), x)
+	default:
+		var acc T
+		for i := 0; i < w.log.count; i++ {
+			acc = w.add(acc, w.log.buf[(w.log.head+i)%len(w.log.buf)])
+		}
+		w.acc = acc
+	}
+}
+
+// Value returns the aggregate of the samples in the window.
+func (w *SlidingWindow[T]) Value() T {
+	w.log.lock.RLock()
+	defer w.log.lock.RUnlock()
+	return w.acc
+}
+
+// Size returns the number of samples in the window.
+func (w *SlidingWindow[T]) Size() int {
+	return w.log.Size()
+}
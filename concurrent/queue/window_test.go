@@ -0,0 +1,85 @@
+package queue
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSlidingWindow(t *testing.T) {
+	Convey("test SlidingWindow", t, func() {
+		Convey("test SlidingWindow sum", func() {
+			w := NewSlidingWindow[int](3,
+				func(acc, x int) int { return acc + x },
+				func(acc, x int) int { return acc - x })
+			So(w.Value(), ShouldEqual, 0)
+			w.Push(1)
+			w.Push(2)
+			So(w.Value(), ShouldEqual, 3)
+			w.Push(3)
+			So(w.Value(), ShouldEqual, 6)
+			// Wrap around the window twice.
+			for x := 4; x <= 8; x++ {
+				w.Push(x)
+				So(w.Value(), ShouldEqual, (x-2)+(x-1)+x)
+			}
+			So(w.Size(), ShouldEqual, 3)
+		})
+
+		Convey("test SlidingWindow average", func() {
+			type avg struct{ sum, n float64 }
+			w := NewSlidingWindow[avg](4,
+				func(acc, x avg) avg { return avg{acc.sum + x.sum, acc.n + x.n} },
+				func(acc, x avg) avg { return avg{acc.sum - x.sum, acc.n - x.n} })
+			for _, x := range []float64{10, 20, 30, 40, 50, 60} {
+				w.Push(avg{x, 1})
+			}
+			v := w.Value()
+			So(v.sum/v.n, ShouldEqual, 45)
+		})
+
+		Convey("test SlidingWindow max without remove", func() {
+			w := NewSlidingWindow[int](2, func(acc, x int) int {
+				if x > acc {
+					return x
+				}
+				return acc
+			}, nil)
+			w.Push(5)
+			w.Push(1)
+			So(w.Value(), ShouldEqual, 5)
+			w.Push(3)
+			So(w.Value(), ShouldEqual, 3)
+			w.Push(2)
+			So(w.Value(), ShouldEqual, 3)
+			w.Push(1)
+			So(w.Value(), ShouldEqual, 2)
+		})
+
+		Convey("test SlidingWindow min without remove", func() {
+			// A min needs a starting point other than the zero value, so
+			// samples carry a flag telling apart an empty aggregate.
+			type sample struct {
+				v   int
+				set bool
+			}
+			w := NewSlidingWindow[sample](3, func(acc, x sample) sample {
+				if !acc.set || x.v < acc.v {
+					return x
+				}
+				return acc
+			}, nil)
+			for _, x := range []int{4, 2, 7, 9, 8} {
+				w.Push(sample{x, true})
+			}
+			So(w.Value().v, ShouldEqual, 7)
+		})
+
+		Convey("test NewSlidingWindow panics", func() {
+			So(func() { NewSlidingWindow[int](0, func(acc, x int) int { return acc }, nil) },
+				ShouldPanicWith, "queue: NewSlidingWindow() called with non-positive size")
+			So(func() { NewSlidingWindow[float64](1, nil, nil) },
+				ShouldPanicWith, "queue: NewSlidingWindow() called with nil add")
+		})
+	})
+}
@@ -0,0 +1,102 @@
+// Package wsdeque offers a bounded Chase-Lev work-stealing deque for schedulers.
+package wsdeque
+
+import "sync/atomic"
+
+// WSDeque is a bounded Chase-Lev work-stealing deque. A single owner goroutine pushes and pops
+// at the bottom, while any number of thieves steal from the top.
+//
+// The owner's PushBottom and PopBottom only load and store the indices and never CAS, except
+// when PopBottom races the thieves for the very last element. Steal claims an element with a CAS
+// on top. Slots are atomic pointers so that a thief reading a slot the owner is overwriting is
+// never a data race; the thief's CAS then fails and its read is discarded.
+type WSDeque[T any] struct {
+	top    atomic.Int64
+	bottom atomic.Int64
+	buf    []atomic.Pointer[T]
+	mask   int64
+}
+
+// NewWSDeque returns a new, ready-to-use WSDeque holding up to capacity elements, rounded up to
+// a power of two.
+//
+// Example:
+//
+//	d := wsdeque.NewWSDeque[task](1024)
+//	d.PushBottom(t)          // owner
+//	t, ok := d.PopBottom()   // owner
+//	t, ok = d.Steal()        // any goroutine
+func NewWSDeque[T any](capacity int) *WSDeque[T] {
+	size := 1
+	for size < capacity {
+		size <<= 1
+	}
+	return &WSDeque[T]{
+		buf:  make([]atomic.Pointer[T], size),
+		mask: int64(size - 1),
+	}
+}
+
+// Len returns the number of elements in the deque. Under concurrent stealing it is an estimate.
+func (d *WSDeque[T]) Len() int {
+	n := d.bottom.Load() - d.top.Load()
+	if n < 0 {
+		return 0
+	}
+	return int(n)
+}
+
+// PushBottom inserts an element at the bottom of the deque and returns true, or returns false if
+// the deque is full. Only the owner may call it.
+func (d *WSDeque[T]) PushBottom(val T) bool {
+	b := d.bottom.Load()
+	t := d.top.Load()
+	if b-t > d.mask {
+		return false
+	}
+	d.buf[b&d.mask].Store(&val)
+	d.bottom.Store(b + 1)
+	return true
+}
+
+// PopBottom returns (and removes) the element at the bottom of the deque and true, otherwise it
+// returns a default value and false if the deque is empty. Only the owner may call it.
+func (d *WSDeque[T]) PopBottom() (T, bool) {
+	var zero T
+	b := d.bottom.Load() - 1
+	// Publish the claim on the bottom element before looking at top.
+	d.bottom.Store(b)
+	t := d.top.Load()
+	if t > b {
+		d.bottom.Store(b + 1)
+		return zero, false
+	}
+	p := d.buf[b&d.mask].Load()
+	if t < b {
+		return *p, true
+	}
+	// Last element: race the thieves for it.
+	won := d.top.CompareAndSwap(t, t+1)
+	d.bottom.Store(b + 1)
+	if !won {
+		return zero, false
+	}
+	return *p, true
+}
+
+// Steal returns (and removes) the element at the top of the deque and true, otherwise it returns
+// a default value and false if the deque is empty. Any goroutine may call it.
+func (d *WSDeque[T]) Steal() (T, bool) {
+	for {
+		t := d.top.Load()
+		b := d.bottom.Load()
+		if t >= b {
+			var zero T
+			return zero, false
+		}
+		p := d.buf[t&d.mask].Load()
+		if d.top.CompareAndSwap(t, t+1) {
+			return *p, true
+		}
+	}
+}
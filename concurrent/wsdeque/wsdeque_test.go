@@ -0,0 +1,86 @@
+package wsdeque
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+const (
+	kStealerNum = 8
+	kPushingNum = 200000
+)
+
+func TestWSDeque_Owner(t *testing.T) {
+	d := NewWSDeque[int](3)
+	for i := 0; i != 4; i++ {
+		if !d.PushBottom(i) {
+			t.Error("Push should succeed:", i)
+		}
+	}
+	if d.PushBottom(4) {
+		t.Error("Push should fail on a full deque")
+	}
+	if v, ok := d.Steal(); !ok || v != 0 {
+		t.Error("Invalid result:", v, ok)
+	}
+	for i := 3; i != 0; i-- {
+		if v, ok := d.PopBottom(); !ok || v != i {
+			t.Error("Invalid result:", i, v, ok)
+		}
+	}
+	if _, ok := d.PopBottom(); ok {
+		t.Error("Should be empty!")
+	}
+	if _, ok := d.Steal(); ok {
+		t.Error("Should be empty!")
+	}
+}
+
+func TestWSDeque(t *testing.T) {
+	runtime.GOMAXPROCS(runtime.NumCPU())
+	d := NewWSDeque[int](1024)
+	seen := make([]atomic.Int32, kPushingNum)
+	var done atomic.Bool
+	var wg sync.WaitGroup
+
+	wg.Add(kStealerNum)
+	for i := 0; i != kStealerNum; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				if v, ok := d.Steal(); ok {
+					seen[v].Add(1)
+				} else if done.Load() {
+					return
+				}
+			}
+		}()
+	}
+
+	// Owner: push everything, popping from the bottom now and then and whenever full.
+	for i := 0; i != kPushingNum; i++ {
+		for !d.PushBottom(i) {
+			if v, ok := d.PopBottom(); ok {
+				seen[v].Add(1)
+			}
+		}
+		if i%3 == 0 {
+			if v, ok := d.PopBottom(); ok {
+				seen[v].Add(1)
+			}
+		}
+	}
+	for v, ok := d.PopBottom(); ok; v, ok = d.PopBottom() {
+		seen[v].Add(1)
+	}
+	done.Store(true)
+	wg.Wait()
+
+	for i := range seen {
+		if n := seen[i].Load(); n != 1 {
+			t.Error("Invalid result:", i, n)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+// Package container provides helpers spanning the queue implementations in this module.
+package container
+
+import (
+	lockfreequeue "github.com/eyotang/container/concurrent/lock_free_queue"
+	concurrentqueue "github.com/eyotang/container/concurrent/queue"
+)
+
+// FromRingBuffer drains q into a new LockFreeQueue, preserving FIFO order.
+// The result is a snapshot rather than a live view: q is left empty, and
+// elements pushed to it afterwards do not show up in the returned queue.
+func FromRingBuffer[T comparable](q *concurrentqueue.Queue[T]) *lockfreequeue.LockFreeQueue[T] {
+	lfq := lockfreequeue.NewQueue[T]()
+	for v, ok := q.Pop(); ok; v, ok = q.Pop() {
+		lfq.Push(v)
+	}
+	return lfq
+}
+
+// FromLockFreeQueue drains lfq into a new ring-buffer Queue, preserving FIFO
+// order. The result is a snapshot rather than a live view: lfq is left empty,
+// and elements pushed to it afterwards do not show up in the returned queue.
+func FromLockFreeQueue[T comparable](lfq *lockfreequeue.LockFreeQueue[T]) *concurrentqueue.Queue[T] {
+	q := concurrentqueue.NewQueue[T]()
+	for v, ok := lfq.Pop(); ok; v, ok = lfq.Pop() {
+		q.Push(v)
+	}
+	return q
+}
@@ -0,0 +1,36 @@
+package container
+
+import (
+	"testing"
+
+	concurrentqueue "github.com/eyotang/container/concurrent/queue"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConvert(t *testing.T) {
+	Convey("test queue conversions", t, func() {
+		want := make([]int, 50)
+		for i := range want {
+			want[i] = i * 3
+		}
+		q := concurrentqueue.NewQueueFromSlice(want)
+
+		Convey("test round trip preserves order", func() {
+			lfq := FromRingBuffer(q)
+			So(q.Empty(), ShouldBeTrue)
+			So(lfq.Len(), ShouldEqual, len(want))
+
+			back := FromLockFreeQueue(lfq)
+			So(lfq.Len(), ShouldEqual, 0)
+			So(back.Items(), ShouldResemble, want)
+		})
+
+		Convey("test conversion is a snapshot", func() {
+			lfq := FromRingBuffer(q)
+			q.Push(-1)
+			back := FromLockFreeQueue(lfq)
+			So(back.Items(), ShouldResemble, want)
+			So(q.Items(), ShouldResemble, []int{-1})
+		})
+	})
+}
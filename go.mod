@@ -1,6 +1,6 @@
 module github.com/eyotang/container
 
-go 1.18
+go 1.24
 
 require github.com/smartystreets/goconvey v1.7.2
 
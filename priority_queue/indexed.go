@@ -0,0 +1,129 @@
+// Package priority_queue offers priority queue implementations.
+package priority_queue
+
+// pqItem is a key of an IndexedPQ along with its priority.
+type pqItem[K comparable, P any] struct {
+	key      K
+	priority P
+}
+
+// IndexedPQ is a binary min-heap of keys ordered by priority, with an index from each key to its
+// position in the heap so that priorities can be changed in O(log n), as needed by Dijkstra-style
+// algorithms. It is not goroutine-safe.
+type IndexedPQ[K comparable, P any] struct {
+	items []pqItem[K, P]
+	index map[K]int
+	less  func(a, b P) bool
+}
+
+// NewIndexedPQ returns an empty IndexedPQ. Pop returns the key whose priority is smallest
+// according to less.
+//
+// Example:
+//
+//	pq := priority_queue.NewIndexedPQ[string, int](func(a, b int) bool { return a < b })
+//	pq.Push("a", 3)
+//	pq.Update("a", 1)
+//	key, priority, ok := pq.Pop()
+func NewIndexedPQ[K comparable, P any](less func(a, b P) bool) *IndexedPQ[K, P] {
+	return &IndexedPQ[K, P]{
+		index: make(map[K]int),
+		less:  less,
+	}
+}
+
+// Len returns the number of keys in the queue.
+func (pq *IndexedPQ[K, P]) Len() int {
+	return len(pq.items)
+}
+
+// Contains reports whether key is in the queue.
+func (pq *IndexedPQ[K, P]) Contains(key K) bool {
+	_, ok := pq.index[key]
+	return ok
+}
+
+// Push inserts key with the given priority. If key is already queued, its priority is updated
+// instead.
+func (pq *IndexedPQ[K, P]) Push(key K, priority P) {
+	if _, ok := pq.index[key]; ok {
+		pq.Update(key, priority)
+		return
+	}
+	pq.items = append(pq.items, pqItem[K, P]{key: key, priority: priority})
+	pq.index[key] = len(pq.items) - 1
+	pq.up(len(pq.items) - 1)
+}
+
+// Pop removes and returns the key with the smallest priority along with that priority and true,
+// otherwise it returns default values and false if the queue is empty.
+func (pq *IndexedPQ[K, P]) Pop() (K, P, bool) {
+	if len(pq.items) == 0 {
+		var (
+			key      K
+			priority P
+		)
+		return key, priority, false
+	}
+	top := pq.items[0]
+	last := len(pq.items) - 1
+	pq.swap(0, last)
+	pq.items[last] = pqItem[K, P]{}
+	pq.items = pq.items[:last]
+	delete(pq.index, top.key)
+	if last > 0 {
+		pq.down(0)
+	}
+	return top.key, top.priority, true
+}
+
+// Update changes the priority of key, moving it up or down the heap as needed. It panics if key
+// is not in the queue.
+func (pq *IndexedPQ[K, P]) Update(key K, priority P) {
+	i, ok := pq.index[key]
+	if !ok {
+		panic("priority_queue: Update() called with unknown key")
+	}
+	old := pq.items[i].priority
+	pq.items[i].priority = priority
+	if pq.less(priority, old) {
+		pq.up(i)
+	} else {
+		pq.down(i)
+	}
+}
+
+func (pq *IndexedPQ[K, P]) swap(i, j int) {
+	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
+	pq.index[pq.items[i].key] = i
+	pq.index[pq.items[j].key] = j
+}
+
+func (pq *IndexedPQ[K, P]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !pq.less(pq.items[i].priority, pq.items[parent].priority) {
+			break
+		}
+		pq.swap(i, parent)
+		i = parent
+	}
+}
+
+func (pq *IndexedPQ[K, P]) down(i int) {
+	n := len(pq.items)
+	for {
+		smallest := i
+		if l := 2*i + 1; l < n && pq.less(pq.items[l].priority, pq.items[smallest].priority) {
+			smallest = l
+		}
+		if r := 2*i + 2; r < n && pq.less(pq.items[r].priority, pq.items[smallest].priority) {
+			smallest = r
+		}
+		if smallest == i {
+			return
+		}
+		pq.swap(i, smallest)
+		i = smallest
+	}
+}
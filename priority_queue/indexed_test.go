@@ -0,0 +1,80 @@
+package priority_queue
+
+import (
+	"math/rand"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func intLess(a, b int) bool { return a < b }
+
+// checkHeap asserts the heap property and the consistency of the key index.
+func checkHeap[K comparable, P any](pq *IndexedPQ[K, P]) {
+	So(len(pq.index), ShouldEqual, len(pq.items))
+	for i, item := range pq.items {
+		So(pq.index[item.key], ShouldEqual, i)
+		if i > 0 {
+			So(pq.less(item.priority, pq.items[(i-1)/2].priority), ShouldBeFalse)
+		}
+	}
+}
+
+func TestIndexedPQ(t *testing.T) {
+	Convey("test IndexedPQ", t, func() {
+		Convey("test IndexedPQ pop order", func() {
+			pq := NewIndexedPQ[string, int](intLess)
+			pq.Push("c", 3)
+			pq.Push("a", 1)
+			pq.Push("b", 2)
+			So(pq.Contains("a"), ShouldBeTrue)
+			So(pq.Contains("z"), ShouldBeFalse)
+			for _, want := range []string{"a", "b", "c"} {
+				k, _, ok := pq.Pop()
+				So(ok, ShouldBeTrue)
+				So(k, ShouldEqual, want)
+			}
+			_, _, ok := pq.Pop()
+			So(ok, ShouldBeFalse)
+			So(pq.Contains("a"), ShouldBeFalse)
+		})
+
+		Convey("test IndexedPQ decrease and increase key", func() {
+			pq := NewIndexedPQ[string, int](intLess)
+			pq.Push("a", 10)
+			pq.Push("b", 20)
+			pq.Push("c", 30)
+			pq.Update("c", 5)
+			checkHeap(pq)
+			k, p, _ := pq.Pop()
+			So(k, ShouldEqual, "c")
+			So(p, ShouldEqual, 5)
+
+			pq.Update("a", 25)
+			checkHeap(pq)
+			k, _, _ = pq.Pop()
+			So(k, ShouldEqual, "b")
+			pq.Push("a", 1)
+			So(pq.Len(), ShouldEqual, 1)
+			So(func() { pq.Update("zz", 0) }, ShouldPanic)
+		})
+
+		Convey("test IndexedPQ invariants under random updates", func() {
+			r := rand.New(rand.NewSource(1))
+			pq := NewIndexedPQ[int, int](intLess)
+			for i := 0; i < 200; i++ {
+				pq.Push(i, r.Intn(1000))
+			}
+			for i := 0; i < 500; i++ {
+				pq.Update(r.Intn(200), r.Intn(1000))
+			}
+			checkHeap(pq)
+			last := -1
+			for pq.Len() > 0 {
+				_, p, _ := pq.Pop()
+				So(p, ShouldBeGreaterThanOrEqualTo, last)
+				last = p
+			}
+		})
+	})
+}
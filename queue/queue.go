@@ -1,5 +1,13 @@
 package queue
 
+import (
+	"fmt"
+	"strings"
+)
+
+// maxStringLen is the number of elements String renders before truncating.
+const maxStringLen = 64
+
 // A Queue is a queue of element.
 type Queue struct {
 	// This is a queue, not a deque.
@@ -15,30 +23,67 @@ type Queue struct {
 	head    []interface{}
 	headPos int
 	tail    []interface{}
+	stats   Stats
+
+	// urgent[urgentPos:] holds the elements pushed by PushUrgent, which
+	// are served before both stages.
+	urgent    []interface{}
+	urgentPos int
+}
+
+// Stats counts the reallocations and stage swaps of a Queue over its life.
+// The head stage is never reallocated itself; it only ever takes over the
+// array of the tail stage on a swap.
+type Stats struct {
+	// Grows counts reallocations of the tail stage by PushBack and PushBackAll.
+	Grows int
+	// Swaps counts head/tail exchanges made by PopFront.
+	Swaps int
 }
 
-// len returns the number of items in the queue.
-func (q *Queue) len() int {
-	return len(q.head) - q.headPos + len(q.tail)
+// Stats returns the reallocation and stage swap counts accumulated so far.
+func (q *Queue) Stats() Stats {
+	return q.stats
+}
+
+// Len returns the number of items in the queue.
+func (q *Queue) Len() int {
+	return len(q.urgent) - q.urgentPos + len(q.head) - q.headPos + len(q.tail)
 }
 
 func (q *Queue) Empty() bool {
-	return q.len() == 0
+	return q.Len() == 0
 }
 
 // PushBack adds w to the back of the queue.
 func (q *Queue) PushBack(w interface{}) {
+	if len(q.tail) == cap(q.tail) {
+		q.stats.Grows++
+	}
 	q.tail = append(q.tail, w)
 }
 
+// PushBackAll adds ws to the back of the queue in order. It is equivalent to
+// calling PushBack for each element, but grows the tail stage at most once.
+func (q *Queue) PushBackAll(ws []interface{}) {
+	if len(q.tail)+len(ws) > cap(q.tail) {
+		q.stats.Grows++
+	}
+	q.tail = append(q.tail, ws...)
+}
+
 // PopFront removes and returns the element at the front of the queue.
 func (q *Queue) PopFront() interface{} {
+	if q.urgentPos < len(q.urgent) {
+		return q.popUrgent()
+	}
 	if q.headPos >= len(q.head) {
 		if len(q.tail) == 0 {
 			return nil
 		}
 		// Pick up tail as new head, clear tail.
 		q.head, q.headPos, q.tail = q.tail, 0, q.head[:0]
+		q.stats.Swaps++
 	}
 	w := q.head[q.headPos]
 	q.head[q.headPos] = nil
@@ -46,8 +91,80 @@ func (q *Queue) PopFront() interface{} {
 	return w
 }
 
+// PushUrgent adds w ahead of every element pushed by PushBack, but behind the
+// elements already pushed by PushUrgent, giving a simple two-class priority.
+func (q *Queue) PushUrgent(w interface{}) {
+	q.urgent = append(q.urgent, w)
+}
+
+// popUrgent removes and returns the element at the front of the urgent
+// stage, which must not be empty.
+func (q *Queue) popUrgent() interface{} {
+	w := q.urgent[q.urgentPos]
+	q.urgent[q.urgentPos] = nil
+	q.urgentPos++
+	if q.urgentPos == len(q.urgent) {
+		// Reuse the array for the next urgent elements.
+		q.urgent, q.urgentPos = q.urgent[:0], 0
+	}
+	return w
+}
+
+// DrainTo pops up to max elements into dst, front first, and returns the
+// number popped. It pops no more than len(dst) elements, nor more than the
+// queue holds. Stages are swapped as PopFront would, copying each stage in
+// bulk.
+func (q *Queue) DrainTo(dst []interface{}, max int) int {
+	n := 0
+	for n < max && n < len(dst) && q.urgentPos < len(q.urgent) {
+		dst[n] = q.popUrgent()
+		n++
+	}
+	for n < max && n < len(dst) {
+		if q.headPos >= len(q.head) {
+			if len(q.tail) == 0 {
+				break
+			}
+			// Pick up tail as new head, clear tail.
+			q.head, q.headPos, q.tail = q.tail, 0, q.head[:0]
+			q.stats.Swaps++
+		}
+		live := q.head[q.headPos:]
+		m := copy(dst[n:min(max, len(dst))], live)
+		clear(live[:m])
+		q.headPos += m
+		n += m
+	}
+	return n
+}
+
+// PopBack removes and returns the element at the back of the queue, serving
+// elements in LIFO order. It pops from the end of the tail stage and falls
+// back to the end of the head stage once the tail is empty, then to the last
+// urgent element. All cases are O(1); no stage swap is ever needed.
+func (q *Queue) PopBack() interface{} {
+	var w interface{}
+	if n := len(q.tail); n > 0 {
+		w = q.tail[n-1]
+		q.tail[n-1] = nil
+		q.tail = q.tail[:n-1]
+	} else if n = len(q.head); q.headPos < n {
+		w = q.head[n-1]
+		q.head[n-1] = nil
+		q.head = q.head[:n-1]
+	} else if n = len(q.urgent); q.urgentPos < n {
+		w = q.urgent[n-1]
+		q.urgent[n-1] = nil
+		q.urgent = q.urgent[:n-1]
+	}
+	return w
+}
+
 // PeekFront returns the P4Folder at the front of the queue without removing it.
 func (q *Queue) PeekFront() interface{} {
+	if q.urgentPos < len(q.urgent) {
+		return q.urgent[q.urgentPos]
+	}
 	if q.headPos < len(q.head) {
 		return q.head[q.headPos]
 	}
@@ -57,6 +174,30 @@ func (q *Queue) PeekFront() interface{} {
 	return nil
 }
 
+// PeekBack returns the element at the back of the queue without removing it:
+// the last element of the tail stage, or of the head stage once the tail is
+// empty, or the last urgent element once both stages are. It returns nil if
+// the queue is empty.
+func (q *Queue) PeekBack() interface{} {
+	if n := len(q.tail); n > 0 {
+		return q.tail[n-1]
+	}
+	if n := len(q.head); q.headPos < n {
+		return q.head[n-1]
+	}
+	if n := len(q.urgent); q.urgentPos < n {
+		return q.urgent[n-1]
+	}
+	return nil
+}
+
+// Clear removes every element and drops the backing arrays of all stages, so
+// that a queue which once grew large frees its memory. Stats are kept.
+func (q *Queue) Clear() {
+	q.head, q.headPos, q.tail = nil, 0, nil
+	q.urgent, q.urgentPos = nil, 0
+}
+
 // CleanFront pops any P4Folders that are no longer waiting from the head of the
 // queue, reporting whether any were popped.
 func (q *Queue) CleanFront() (cleaned bool) {
@@ -69,3 +210,26 @@ func (q *Queue) CleanFront() (cleaned bool) {
 		cleaned = true
 	}
 }
+
+// String renders the queue as [e0 e1 ... eN] from front to back. Only the
+// first maxStringLen elements are rendered, followed by an ellipsis.
+func (q *Queue) String() string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	n := 0
+	for _, stage := range [][]interface{}{q.urgent[q.urgentPos:], q.head[q.headPos:], q.tail} {
+		for _, w := range stage {
+			if n > 0 {
+				sb.WriteByte(' ')
+			}
+			if n == maxStringLen {
+				sb.WriteString("...]")
+				return sb.String()
+			}
+			fmt.Fprint(&sb, w)
+			n++
+		}
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
@@ -0,0 +1,369 @@
+package queue
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestQueue_PopBack(t *testing.T) {
+	// pushPattern pushes 0..2, pops 0 from the front so the head stage is
+	// in use, then pushes 3..4 onto the tail stage.
+	pushPattern := func() *Queue {
+		q := &Queue{}
+		for i := 0; i < 3; i++ {
+			q.PushBack(i)
+		}
+		q.PopFront()
+		q.PushBack(3)
+		q.PushBack(4)
+		return q
+	}
+
+	Convey("test Queue PopBack", t, func() {
+		Convey("test Queue FIFO order", func() {
+			q := pushPattern()
+			var got []interface{}
+			for !q.Empty() {
+				got = append(got, q.PopFront())
+			}
+			So(got, ShouldResemble, []interface{}{1, 2, 3, 4})
+		})
+
+		Convey("test Queue LIFO order", func() {
+			q := pushPattern()
+			var got []interface{}
+			for !q.Empty() {
+				got = append(got, q.PopBack())
+			}
+			So(got, ShouldResemble, []interface{}{4, 3, 2, 1})
+			So(q.PopBack(), ShouldBeNil)
+		})
+
+		Convey("test Queue mixed order", func() {
+			q := pushPattern()
+			So(q.PopBack(), ShouldEqual, 4)
+			So(q.PopFront(), ShouldEqual, 1)
+			So(q.PopBack(), ShouldEqual, 3)
+			So(q.PopBack(), ShouldEqual, 2)
+			So(q.Empty(), ShouldBeTrue)
+			q.PushBack(5)
+			So(q.PopFront(), ShouldEqual, 5)
+		})
+	})
+}
+
+func TestQueue_PeekBack(t *testing.T) {
+	Convey("test Queue PeekBack", t, func() {
+		Convey("test Queue PeekBack empty", func() {
+			q := &Queue{}
+			So(q.PeekBack(), ShouldBeNil)
+		})
+
+		Convey("test Queue PeekBack tail only", func() {
+			q := &Queue{}
+			q.PushBack(1)
+			q.PushBack(2)
+			So(q.PeekBack(), ShouldEqual, 2)
+			So(q.Len(), ShouldEqual, 2)
+		})
+
+		Convey("test Queue PeekBack head only", func() {
+			q := &Queue{}
+			for i := 0; i < 3; i++ {
+				q.PushBack(i)
+			}
+			// Popping swaps the stages, leaving 1 and 2 in the head stage.
+			q.PopFront()
+			So(q.PeekBack(), ShouldEqual, 2)
+			q.PopFront()
+			q.PopFront()
+			So(q.PeekBack(), ShouldBeNil)
+		})
+	})
+}
+
+func TestQueue_PushUrgent(t *testing.T) {
+	Convey("test Queue PushUrgent", t, func() {
+		q := &Queue{}
+		q.PushBack(1)
+		q.PushUrgent("u1")
+		q.PushBack(2)
+		q.PushUrgent("u2")
+		So(q.Len(), ShouldEqual, 4)
+		So(q.PeekFront(), ShouldEqual, "u1")
+		So(q.PeekBack(), ShouldEqual, 2)
+		So(q.String(), ShouldEqual, "[u1 u2 1 2]")
+
+		Convey("test Queue PushUrgent FIFO among urgent", func() {
+			So(q.PopFront(), ShouldEqual, "u1")
+			q.PushUrgent("u3")
+			q.PushBack(3)
+			var got []interface{}
+			for !q.Empty() {
+				got = append(got, q.PopFront())
+			}
+			So(got, ShouldResemble, []interface{}{"u2", "u3", 1, 2, 3})
+
+			// The urgent stage is reusable once drained.
+			q.PushBack(4)
+			q.PushUrgent("u4")
+			So(q.PopFront(), ShouldEqual, "u4")
+			So(q.PopFront(), ShouldEqual, 4)
+			So(q.PopFront(), ShouldBeNil)
+		})
+
+		Convey("test Queue PushUrgent PopBack", func() {
+			var got []interface{}
+			for !q.Empty() {
+				got = append(got, q.PopBack())
+			}
+			So(got, ShouldResemble, []interface{}{2, 1, "u2", "u1"})
+			So(q.PeekBack(), ShouldBeNil)
+		})
+
+		Convey("test Queue PushUrgent DrainTo", func() {
+			dst := make([]interface{}, 3)
+			So(q.DrainTo(dst, 3), ShouldEqual, 3)
+			So(dst, ShouldResemble, []interface{}{"u1", "u2", 1})
+			So(q.Len(), ShouldEqual, 1)
+		})
+	})
+}
+
+func TestQueue_Clear(t *testing.T) {
+	Convey("test Queue Clear", t, func() {
+		q := &Queue{}
+		for i := 0; i < 1000; i++ {
+			q.PushBack(i)
+		}
+		q.PopFront()
+		for i := 0; i < 1000; i++ {
+			q.PushBack(i)
+		}
+		q.PushUrgent(-1)
+		q.Clear()
+		So(q.Empty(), ShouldBeTrue)
+		So(q.PopFront(), ShouldBeNil)
+		So(q.PeekBack(), ShouldBeNil)
+		So(cap(q.head)+cap(q.tail)+cap(q.urgent), ShouldEqual, 0)
+
+		for i := 0; i < 3; i++ {
+			q.PushBack(i)
+		}
+		q.PushUrgent(-1)
+		var got []interface{}
+		for !q.Empty() {
+			got = append(got, q.PopFront())
+		}
+		So(got, ShouldResemble, []interface{}{-1, 0, 1, 2})
+	})
+}
+
+func TestQueue_String(t *testing.T) {
+	Convey("test Queue String", t, func() {
+		Convey("test Queue String empty", func() {
+			So((&Queue{}).String(), ShouldEqual, "[]")
+		})
+
+		Convey("test Queue String across both stages", func() {
+			q := &Queue{}
+			q.PushBack(1)
+			q.PushBack("two")
+			q.PopFront()
+			q.PushBack(3)
+			q.PushBack(4)
+			So(q.String(), ShouldEqual, "[two 3 4]")
+		})
+
+		Convey("test Queue String truncated", func() {
+			q := &Queue{}
+			for i := 0; i < maxStringLen+10; i++ {
+				q.PushBack(0)
+			}
+			So(q.String(), ShouldEqual, "["+strings.Repeat("0 ", maxStringLen)+"...]")
+		})
+	})
+}
+
+func TestQueue_PushBackAll(t *testing.T) {
+	Convey("test Queue PushBackAll", t, func() {
+		batch, single := &Queue{}, &Queue{}
+		batch.PushBack(-1)
+		single.PushBack(-1)
+		batch.PopFront()
+		single.PopFront()
+
+		ws := []interface{}{1, "two", 3.0, nil, 5}
+		batch.PushBackAll(ws)
+		for _, w := range ws {
+			single.PushBack(w)
+		}
+		So(batch.Len(), ShouldEqual, len(ws))
+		So(batch.Len(), ShouldEqual, single.Len())
+
+		for single.Len() > 0 {
+			So(batch.PopFront(), ShouldEqual, single.PopFront())
+		}
+		So(batch.Len(), ShouldEqual, 0)
+	})
+}
+
+func TestQueue_DrainTo(t *testing.T) {
+	// pushPattern leaves 1..3 in the head stage and 4..5 in the tail stage.
+	pushPattern := func() *Queue {
+		q := &Queue{}
+		for i := 0; i < 4; i++ {
+			q.PushBack(i)
+		}
+		q.PopFront()
+		q.PushBack(4)
+		q.PushBack(5)
+		return q
+	}
+
+	Convey("test Queue DrainTo", t, func() {
+		Convey("test Queue DrainTo fewer than available", func() {
+			q := pushPattern()
+			dst := make([]interface{}, 10)
+			So(q.DrainTo(dst, 2), ShouldEqual, 2)
+			So(dst[:2], ShouldResemble, []interface{}{1, 2})
+			So(dst[2], ShouldBeNil)
+			So(q.Len(), ShouldEqual, 3)
+			So(q.PopFront(), ShouldEqual, 3)
+		})
+
+		Convey("test Queue DrainTo exactly available", func() {
+			q := pushPattern()
+			dst := make([]interface{}, 5)
+			So(q.DrainTo(dst, 5), ShouldEqual, 5)
+			So(dst, ShouldResemble, []interface{}{1, 2, 3, 4, 5})
+			So(q.Empty(), ShouldBeTrue)
+			So(q.Stats().Swaps, ShouldEqual, 2)
+		})
+
+		Convey("test Queue DrainTo more than available", func() {
+			q := pushPattern()
+			dst := make([]interface{}, 10)
+			So(q.DrainTo(dst, 10), ShouldEqual, 5)
+			So(dst[:5], ShouldResemble, []interface{}{1, 2, 3, 4, 5})
+			So(q.DrainTo(dst, 10), ShouldEqual, 0)
+			q.PushBack(6)
+			So(q.PeekFront(), ShouldEqual, 6)
+		})
+
+		Convey("test Queue DrainTo bounded by dst", func() {
+			q := pushPattern()
+			dst := make([]interface{}, 4)
+			So(q.DrainTo(dst, 10), ShouldEqual, 4)
+			So(dst, ShouldResemble, []interface{}{1, 2, 3, 4})
+			So(q.PopFront(), ShouldEqual, 5)
+		})
+	})
+}
+
+func TestRoundRobinQueue(t *testing.T) {
+	Convey("test RoundRobinQueue", t, func() {
+		q := NewRoundRobinQueue[string, int]()
+		q.AddSource("a")
+		q.AddSource("b")
+		q.AddSource("c")
+		q.AddSource("a")
+
+		Convey("test RoundRobinQueue uneven sources", func() {
+			for i := 0; i < 4; i++ {
+				q.PushBack("a", 10+i)
+			}
+			q.PushBack("b", 20)
+			for i := 0; i < 2; i++ {
+				q.PushBack("c", 30+i)
+			}
+			So(q.Len(), ShouldEqual, 7)
+
+			var got []int
+			for v, ok := q.PopFront(); ok; v, ok = q.PopFront() {
+				got = append(got, v)
+			}
+			So(got, ShouldResemble, []int{10, 20, 30, 11, 31, 12, 13})
+			So(q.Len(), ShouldEqual, 0)
+		})
+
+		Convey("test RoundRobinQueue rotation resumes after refill", func() {
+			q.PushBack("a", 1)
+			q.PushBack("b", 2)
+			v, _ := q.PopFront()
+			So(v, ShouldEqual, 1)
+			q.PushBack("a", 3)
+			v, _ = q.PopFront()
+			So(v, ShouldEqual, 2)
+			v, _ = q.PopFront()
+			So(v, ShouldEqual, 3)
+		})
+
+		Convey("test RoundRobinQueue nil item", func() {
+			eq := NewRoundRobinQueue[string, error]()
+			eq.AddSource("a")
+			eq.PushBack("a", nil)
+			So(eq.Len(), ShouldEqual, 1)
+			var (
+				v  error
+				ok bool
+			)
+			So(func() { v, ok = eq.PopFront() }, ShouldNotPanic)
+			So(ok, ShouldBeTrue)
+			So(v, ShouldBeNil)
+			So(eq.Len(), ShouldEqual, 0)
+		})
+
+		Convey("test RoundRobinQueue unknown source", func() {
+			So(func() { q.PushBack("d", 1) }, ShouldPanic)
+			_, ok := NewRoundRobinQueue[int, int]().PopFront()
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
+
+func TestQueue_Stats(t *testing.T) {
+	Convey("test Queue Stats", t, func() {
+		Convey("test Queue Stats swaps", func() {
+			q := &Queue{}
+			q.PushBack(1)
+			q.PushBack(2)
+			So(q.Stats().Swaps, ShouldEqual, 0)
+
+			// The first pop exchanges the stages, the second one doesn't.
+			q.PopFront()
+			So(q.Stats().Swaps, ShouldEqual, 1)
+			q.PopFront()
+			So(q.Stats().Swaps, ShouldEqual, 1)
+
+			// Popping an empty queue has nothing to swap in.
+			q.PopFront()
+			So(q.Stats().Swaps, ShouldEqual, 1)
+
+			q.PushBack(3)
+			q.PopFront()
+			So(q.Stats().Swaps, ShouldEqual, 2)
+		})
+
+		Convey("test Queue Stats grows", func() {
+			q := &Queue{}
+			q.PushBack(1)
+			So(q.Stats().Grows, ShouldEqual, 1)
+			for i := 0; i < 100; i++ {
+				q.PushBack(i)
+			}
+			grows := q.Stats().Grows
+			So(grows, ShouldBeGreaterThan, 1)
+			So(grows, ShouldBeLessThan, 20)
+
+			// The first swap hands the tail the empty initial head, so it grows again.
+			q.PopFront()
+			q.PushBack(1)
+			So(q.Stats().Grows, ShouldEqual, grows+1)
+			q.PushBackAll([]interface{}{2, 3})
+			So(q.Stats().Grows, ShouldEqual, grows+2)
+		})
+	})
+}
@@ -0,0 +1,62 @@
+package queue
+
+// A RoundRobinQueue interleaves the items of several sources fairly. Each
+// source registered with AddSource gets its own Queue, and PopFront serves
+// the sources in rotation, skipping the empty ones.
+type RoundRobinQueue[K comparable, T any] struct {
+	ids    []K
+	queues map[K]*Queue
+	next   int
+	len    int
+}
+
+// NewRoundRobinQueue returns an empty RoundRobinQueue.
+func NewRoundRobinQueue[K comparable, T any]() *RoundRobinQueue[K, T] {
+	return &RoundRobinQueue[K, T]{
+		queues: make(map[K]*Queue),
+	}
+}
+
+// AddSource registers the source id. Adding a source twice has no effect.
+func (q *RoundRobinQueue[K, T]) AddSource(id K) {
+	if _, ok := q.queues[id]; ok {
+		return
+	}
+	q.ids = append(q.ids, id)
+	q.queues[id] = &Queue{}
+}
+
+// Len returns the number of items queued across all sources.
+func (q *RoundRobinQueue[K, T]) Len() int {
+	return q.len
+}
+
+// PushBack adds item to the back of the queue of source id, which must have
+// been registered with AddSource.
+func (q *RoundRobinQueue[K, T]) PushBack(id K, item T) {
+	sq, ok := q.queues[id]
+	if !ok {
+		panic("queue: PushBack() called with unknown source")
+	}
+	sq.PushBack(item)
+	q.len++
+}
+
+// PopFront removes and returns the front item of the next non-empty source in
+// rotation and true, or a default value and false if all sources are empty.
+func (q *RoundRobinQueue[K, T]) PopFront() (T, bool) {
+	for i := range q.ids {
+		idx := (q.next + i) % len(q.ids)
+		sq := q.queues[q.ids[idx]]
+		if sq.Empty() {
+			continue
+		}
+		q.next = idx + 1
+		q.len--
+		// A nil item of an interface type T comes back as a nil interface{}.
+		w, _ := sq.PopFront().(T)
+		return w, true
+	}
+	var v T
+	return v, false
+}
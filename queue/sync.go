@@ -0,0 +1,51 @@
+package queue
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// A SyncQueue is a goroutine-safe wrapper around Queue. Structural
+// operations are serialized by a mutex, while the length is mirrored in an
+// atomic counter so that Len never takes the lock.
+type SyncQueue struct {
+	mu     sync.Mutex
+	q      Queue
+	length atomic.Int64
+}
+
+// Len returns the number of items in the queue with a single atomic load.
+func (s *SyncQueue) Len() int {
+	return int(s.length.Load())
+}
+
+func (s *SyncQueue) Empty() bool {
+	return s.Len() == 0
+}
+
+// PushBack adds w to the back of the queue.
+func (s *SyncQueue) PushBack(w interface{}) {
+	s.mu.Lock()
+	s.q.PushBack(w)
+	s.length.Add(1)
+	s.mu.Unlock()
+}
+
+// PopFront removes and returns the element at the front of the queue, or
+// nil if the queue is empty.
+func (s *SyncQueue) PopFront() interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.q.Empty() {
+		return nil
+	}
+	s.length.Add(-1)
+	return s.q.PopFront()
+}
+
+// PeekFront returns the element at the front of the queue without removing it.
+func (s *SyncQueue) PeekFront() interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.q.PeekFront()
+}
@@ -0,0 +1,49 @@
+package queue
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSyncQueue(t *testing.T) {
+	Convey("test SyncQueue", t, func() {
+		Convey("test SyncQueue empty", func() {
+			var s SyncQueue
+			So(s.PopFront(), ShouldBeNil)
+			So(s.Len(), ShouldEqual, 0)
+		})
+
+		Convey("test SyncQueue length at quiescence", func() {
+			const goroutines, n = 8, 5000
+			var s SyncQueue
+			var wg sync.WaitGroup
+			wg.Add(goroutines * 2)
+			for i := 0; i < goroutines; i++ {
+				go func() {
+					defer wg.Done()
+					for j := 0; j < n; j++ {
+						s.PushBack(j)
+					}
+				}()
+				go func() {
+					defer wg.Done()
+					for j := 0; j < n/2; j++ {
+						s.PopFront()
+					}
+				}()
+			}
+			wg.Wait()
+
+			s.mu.Lock()
+			locked := s.q.Len()
+			s.mu.Unlock()
+			So(s.Len(), ShouldEqual, locked)
+			for s.PopFront() != nil {
+			}
+			So(s.Len(), ShouldEqual, 0)
+			So(s.Empty(), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,81 @@
+/*
+Package ringbuffer provides a fixed-capacity byte ring buffer implementing
+io.Reader and io.Writer, usable as a goroutine-safe in-memory pipe.
+*/
+package ringbuffer
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrFull is returned by Write when the buffer has no room for all of p.
+var ErrFull = errors.New("ringbuffer: buffer is full")
+
+// RingBuffer represents a single instance of the ring buffer.
+type RingBuffer struct {
+	buf         []byte
+	head, count int
+	lock        sync.Mutex
+}
+
+// New constructs and returns a new RingBuffer holding at most capacity bytes.
+// This call panics if capacity is not positive.
+func New(capacity int) *RingBuffer {
+	if capacity <= 0 {
+		panic("ringbuffer: New() called with non-positive capacity")
+	}
+	return &RingBuffer{
+		buf: make([]byte, capacity),
+	}
+}
+
+// Len returns the number of bytes available for reading.
+func (r *RingBuffer) Len() int {
+	r.lock.Lock()
+	count := r.count
+	r.lock.Unlock()
+	return count
+}
+
+// Cap returns the capacity of the buffer.
+func (r *RingBuffer) Cap() int {
+	return len(r.buf)
+}
+
+// Write appends as much of p as fits in the free space. If not all of p
+// fits, it returns the short count along with ErrFull.
+func (r *RingBuffer) Write(p []byte) (n int, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if free := len(r.buf) - r.count; len(p) > free {
+		p, err = p[:free], ErrFull
+	}
+	tail := (r.head + r.count) % len(r.buf)
+	n = copy(r.buf[tail:], p)
+	n += copy(r.buf, p[n:])
+	r.count += n
+	return n, err
+}
+
+// Read drains up to len(p) bytes into p. It returns io.EOF if the buffer is
+// currently empty; more data may still be written afterwards.
+func (r *RingBuffer) Read(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.count == 0 {
+		return 0, io.EOF
+	}
+	if len(p) > r.count {
+		p = p[:r.count]
+	}
+	n = copy(p, r.buf[r.head:])
+	n += copy(p[n:], r.buf)
+	r.head = (r.head + n) % len(r.buf)
+	r.count -= n
+	return n, nil
+}
@@ -0,0 +1,61 @@
+package ringbuffer
+
+import (
+	"io"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRingBuffer(t *testing.T) {
+	Convey("test RingBuffer", t, func() {
+		r := New(8)
+
+		Convey("test RingBuffer partial write", func() {
+			n, err := r.Write([]byte("hello"))
+			So(n, ShouldEqual, 5)
+			So(err, ShouldBeNil)
+			n, err = r.Write([]byte("world"))
+			So(n, ShouldEqual, 3)
+			So(err, ShouldEqual, ErrFull)
+			So(r.Len(), ShouldEqual, 8)
+
+			p := make([]byte, 16)
+			n, err = r.Read(p)
+			So(err, ShouldBeNil)
+			So(string(p[:n]), ShouldEqual, "hellowor")
+		})
+
+		Convey("test RingBuffer wrap-around read", func() {
+			r.Write([]byte("abcdef"))
+			p := make([]byte, 4)
+			n, _ := r.Read(p)
+			So(string(p[:n]), ShouldEqual, "abcd")
+			n, err := r.Write([]byte("ghijkl"))
+			So(n, ShouldEqual, 6)
+			So(err, ShouldBeNil)
+
+			p = make([]byte, 5)
+			n, _ = r.Read(p)
+			So(string(p[:n]), ShouldEqual, "efghi")
+			n, _ = r.Read(p)
+			So(string(p[:n]), ShouldEqual, "jkl")
+		})
+
+		Convey("test RingBuffer full and empty", func() {
+			n, err := r.Write([]byte("12345678"))
+			So(n, ShouldEqual, 8)
+			So(err, ShouldBeNil)
+			n, err = r.Write([]byte("9"))
+			So(n, ShouldEqual, 0)
+			So(err, ShouldEqual, ErrFull)
+
+			data, err := io.ReadAll(r)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "12345678")
+			n, err = r.Read(make([]byte, 1))
+			So(n, ShouldEqual, 0)
+			So(err, ShouldEqual, io.EOF)
+		})
+	})
+}